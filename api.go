@@ -1,13 +1,21 @@
 package grepo
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"reflect"
+	"runtime/debug"
+	"slices"
 	"sort"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/ralsnet/grepo/refl"
 )
 
 type APIOptions struct {
@@ -15,6 +23,13 @@ type APIOptions struct {
 	enableInputValidation  bool
 	enableOutputValidation bool
 	customFieldValidators  []FieldValidator
+	namedFieldValidators   map[string]FieldValidator
+	structValidators       map[reflect.Type][]StructValidatorFunc
+	recoverPanics          bool
+	defaultTimeout         time.Duration
+	clock                  Clock
+	idGenerator            func() string
+	authorizer             Authorizer
 }
 
 type APIOptionFunc func(*APIOptions)
@@ -25,6 +40,30 @@ func WithFixedTime(t time.Time) APIOptionFunc {
 	}
 }
 
+// WithClock sets the Clock used for execution times, timeouts and retry
+// backoff. WithFixedTime still takes precedence for ExecuteTime.
+func WithClock(c Clock) APIOptionFunc {
+	return func(o *APIOptions) {
+		o.clock = c
+	}
+}
+
+// WithIDGenerator replaces the generator of ExecutionInfo IDs, which by default
+// returns 32 random hex digits.
+func WithIDGenerator(fn func() string) APIOptionFunc {
+	return func(o *APIOptions) {
+		o.idGenerator = fn
+	}
+}
+
+// WithAuthorizer replaces RequirementsAuthorizer, the default Authorizer. It
+// is called for every execution, with or without requirements.
+func WithAuthorizer(authorizer Authorizer) APIOptionFunc {
+	return func(o *APIOptions) {
+		o.authorizer = authorizer
+	}
+}
+
 func WithEnableInputValidation() APIOptionFunc {
 	return func(o *APIOptions) {
 		o.enableInputValidation = true
@@ -37,15 +76,58 @@ func WithEnableOutputValidation() APIOptionFunc {
 	}
 }
 
+// WithPanicRecovery recovers panics raised by hooks and use cases and reports
+// them as a CodeInternal Error, caused by a PanicError, through the error hooks.
+func WithPanicRecovery() APIOptionFunc {
+	return func(o *APIOptions) {
+		o.recoverPanics = true
+	}
+}
+
+// WithDefaultTimeout bounds every operation that neither its use case nor its
+// groups give a timeout.
+func WithDefaultTimeout(d time.Duration) APIOptionFunc {
+	return func(o *APIOptions) {
+		o.defaultTimeout = d
+	}
+}
+
 func WithCustomFieldValidators(validators ...FieldValidator) APIOptionFunc {
 	return func(o *APIOptions) {
 		o.customFieldValidators = append(o.customFieldValidators, validators...)
 	}
 }
 
+// WithNamedFieldValidator registers a validator that runs only on fields
+// referencing name in their grepo:"custom:..." tag.
+func WithNamedFieldValidator(name string, validator FieldValidator) APIOptionFunc {
+	return func(o *APIOptions) {
+		if o.namedFieldValidators == nil {
+			o.namedFieldValidators = make(map[string]FieldValidator)
+		}
+		o.namedFieldValidators[name] = validator
+	}
+}
+
+// WithStructValidator registers a struct-level validator for T that runs after
+// the tag constraints of T's fields, wherever T appears in an input or output.
+func WithStructValidator[T any](fn func(ctx context.Context, v T) error) APIOptionFunc {
+	return func(o *APIOptions) {
+		if o.structValidators == nil {
+			o.structValidators = make(map[reflect.Type][]StructValidatorFunc)
+		}
+		t := reflect.TypeFor[T]()
+		o.structValidators[t] = append(o.structValidators[t], func(ctx context.Context, v any) error {
+			return fn(ctx, v.(T))
+		})
+	}
+}
+
 type API struct {
 	description string
 	m           map[string]Descriptor
+	plans       map[string]*plan
+	types       sync.Map
 	root        *Group
 	options     *APIOptions
 }
@@ -53,6 +135,7 @@ type API struct {
 func newAPI() *API {
 	return &API{
 		m:       make(map[string]Descriptor),
+		plans:   make(map[string]*plan),
 		root:    NewGroup("root"),
 		options: &APIOptions{},
 	}
@@ -72,6 +155,9 @@ func UseCase[I any, O any](api *API, op string) Executor[I, O] {
 	}))
 }
 
+// UseCaseByIO returns an Executor for the use case taking I and returning O.
+// BuildE rejects use cases that share those types; in an API built with Build
+// the first by operation name is used, and UseCase picks another.
 func UseCaseByIO[I any, O any](api *API) Executor[I, O] {
 	return (ExecutorFunc[I, O](func(ctx context.Context, input I) (*O, error) {
 		var uc Descriptor
@@ -128,113 +214,166 @@ func (a *API) ExecuteAny(ctx context.Context, operation string, input any) (any,
 }
 
 func (a *API) executeUseCase(ctx context.Context, uc Descriptor, input any) (output any, err error) {
-	interactorType := reflect.TypeOf(uc)
-	interactorValue := reflect.ValueOf(uc)
-	for interactorType.Kind() == reflect.Pointer {
-		interactorType = interactorType.Elem()
+	p := a.planOf(uc)
+
+	clock := a.clock()
+	ctx = withClock(ctx, clock)
+	ctx = withExecutionInfo(ctx, a.newExecutionInfo(ctx, uc, clock.Now()))
+
+	timeout := a.Timeout(uc)
+	if timeout > 0 {
+		// Cancelled only after the error hooks below have run.
+		var cancel context.CancelFunc
+		ctx, cancel = withTimeout(ctx, clock, timeout)
+		defer cancel()
 	}
 
-	// Create a pointer to input value
-	ptr := reflect.New(reflect.ValueOf(input).Type())
-	ptr.Elem().Set(reflect.ValueOf(input))
-	inputPtr := ptr.Interface()
-
-	groups := append([]*Group{a.root}, uc.Groups()...)
-
 	defer func() {
 		if err != nil {
 			output = nil
-			hookError(ctx, uc, ptr.Elem().Interface(), err, groups)
-			a.doErrorHook(ctx, interactorValue, ptr.Elem().Interface(), err)
+			err = deadlineError(err, timeout)
+			hookError(ctx, uc, input, err, p.groups)
+			p.interactor.errorAny(ctx, input, err)
 		}
 	}()
 
+	if a.options.recoverPanics {
+		// Runs before the deferred error hooks, which then see the panic as err.
+		defer func() {
+			if r := recover(); r != nil {
+				output = nil
+				err = newPanicError(r, debug.Stack())
+			}
+		}()
+	}
+
 	if a.options.fixedTime != nil {
 		ctx = WithExecuteTime(ctx, *a.options.fixedTime)
 	} else {
-		ctx = WithExecuteTime(ctx, time.Now())
+		ctx = WithExecuteTime(ctx, clock.Now())
 	}
 
-	ctx, err = hookBefore(ctx, uc, ptr.Elem().Interface(), groups)
-	if err != nil {
+	if err = a.authorize(ctx, uc); err != nil {
 		return nil, err
 	}
 
-	ctx, err = a.doBeforeHook(ctx, interactorValue, inputPtr)
+	if p.defaults != nil {
+		input = applyDefaults(input, p.input, p.defaults)
+	}
+
+	c, err := hookBefore(ctx, uc, input, p.groups)
+	if c != nil {
+		ctx = c
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	input = ptr.Elem().Interface()
+	c, input, err = p.interactor.beforeAny(ctx, input)
+	if c != nil {
+		ctx = c
+	}
+	if err != nil {
+		return nil, err
+	}
 
 	if a.options.enableInputValidation {
-		if err = Validate(input, a.options.customFieldValidators...); err != nil {
+		if err = a.validate(ctx, input, p.input); err != nil {
 			return nil, err
 		}
 	}
 
-	execute := interactorValue.MethodByName("Execute")
-	if !execute.IsValid() {
-		return nil, ErrNotFound
-	}
-	o := execute.Call([]reflect.Value{reflect.ValueOf(ctx), reflect.ValueOf(input)})
-	if len(o) != 2 {
-		return nil, ErrInvalid
-	}
-
-	output = o[0].Interface()
-	err, _ = o[1].Interface().(error)
+	ctx, output, err = a.retry(ctx, a.RetryPolicy(uc), func(ctx context.Context) (any, error) {
+		return hookMiddleware(ctx, uc, input, p.groups, func(ctx context.Context, input any) (any, error) {
+			return p.interactor.aroundAny(ctx, input, p.interactor.executeAny)
+		})
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	if a.options.enableOutputValidation {
-		if err = Validate(output, a.options.customFieldValidators...); err != nil {
-			return nil, err
+		if err = a.validate(ctx, output, p.output); err != nil {
+			// An invalid output is a bug in the use case, not in the caller's
+			// request, so it is reported as an internal error.
+			return nil, WrapError(CodeInternal, err, "invalid output")
 		}
 	}
 
-	hookAfter(ctx, uc, input, output, groups)
-	a.doAfterHook(ctx, interactorValue, input, output)
+	hookAfter(ctx, uc, input, output, p.groups)
+	p.interactor.afterAny(ctx, input, output)
 	return output, nil
 }
 
-func (a *API) doBeforeHook(ctx context.Context, interactor reflect.Value, input any) (context.Context, error) {
-	doBeforeHook := interactor.MethodByName("DoBeforeHook")
-	if !doBeforeHook.IsValid() {
-		return nil, ErrNotFound
+func (a *API) clock() Clock {
+	if a.options.clock != nil {
+		return a.options.clock
 	}
+	return RealClock()
+}
 
-	o := doBeforeHook.Call([]reflect.Value{reflect.ValueOf(ctx), reflect.ValueOf(input)})
-	if len(o) != 2 {
-		return nil, ErrInvalid
+func (a *API) newExecutionInfo(ctx context.Context, d Descriptor, start time.Time) *ExecutionInfo {
+	newID := a.options.idGenerator
+	if newID == nil {
+		newID = newExecutionID
 	}
-	ctxInterface, ok := o[0].Interface().(context.Context)
-	if !ok {
-		return ctx, ErrInvalid
+	info := &ExecutionInfo{
+		ID:        newID(),
+		Operation: d.Operation(),
+		Transport: TransportFrom(ctx),
+		Principal: PrincipalFrom(ctx),
+		StartTime: start,
 	}
-	ctx = ctxInterface
-	errInterface, ok := o[1].Interface().(error)
-	if ok {
-		return ctx, errInterface
+	if parent := ExecutionInfoFrom(ctx); parent != nil {
+		info.ParentID = parent.ID
 	}
-	return ctx, nil
+	return info
 }
 
-func (a *API) doAfterHook(ctx context.Context, interactor reflect.Value, input any, output any) {
-	doAfterHook := interactor.MethodByName("DoAfterHook")
-	if !doAfterHook.IsValid() {
-		return
+// Timeout returns the timeout applied to d: its own, else the shortest among
+// its groups, else the API default. Zero means no timeout.
+func (a *API) Timeout(d Descriptor) time.Duration {
+	if t, ok := d.(interface{ Timeout() time.Duration }); ok && t.Timeout() > 0 {
+		return t.Timeout()
+	}
+	var timeout time.Duration
+	for _, g := range d.Groups() {
+		if g.timeout > 0 && (timeout == 0 || g.timeout < timeout) {
+			timeout = g.timeout
+		}
+	}
+	if timeout == 0 {
+		timeout = a.options.defaultTimeout
 	}
-	doAfterHook.Call([]reflect.Value{reflect.ValueOf(ctx), reflect.ValueOf(input), reflect.ValueOf(output)})
+	return timeout
 }
 
-func (a *API) doErrorHook(ctx context.Context, interactor reflect.Value, input any, e error) {
-	doErrorHook := interactor.MethodByName("DoErrorHook")
-	if !doErrorHook.IsValid() {
-		return
+// ErrorCodes returns the error codes d declares, plus those implied by its
+// timeout and its requirements.
+func (a *API) ErrorCodes(d Descriptor) []Code {
+	codes := slices.Clone(ErrorCodesOf(d))
+	implied := make([]Code, 0)
+	if a.Timeout(d) > 0 {
+		implied = append(implied, CodeDeadlineExceeded)
+	}
+	if !a.Requirements(d).IsZero() {
+		implied = append(implied, CodeUnauthenticated, CodePermissionDenied)
+	}
+	for _, code := range implied {
+		if !slices.Contains(codes, code) {
+			codes = append(codes, code)
+		}
 	}
-	doErrorHook.Call([]reflect.Value{reflect.ValueOf(ctx), reflect.ValueOf(input), reflect.ValueOf(e)})
+	return codes
+}
+
+func (a *API) validate(ctx context.Context, v any, t *refl.Type) error {
+	return validateWith(ctx, v, t, &validator{
+		validators: a.options.customFieldValidators,
+		named:      a.options.namedFieldValidators,
+		structs:    a.options.structValidators,
+		types:      &a.types,
+	})
 }
 
 func (a *API) MarshalJSON() ([]byte, error) {
@@ -253,6 +392,21 @@ func (a *API) MarshalJSON() ([]byte, error) {
 			b.WriteString(",")
 		}
 		ucJSON, _ := json.Marshal(d)
+		if constraints := a.customConstraints(d); len(constraints) > 0 {
+			ucJSON = appendJSONField(ucJSON, "CustomConstraints", constraints)
+		}
+		if codes := a.ErrorCodes(d); len(codes) > 0 {
+			ucJSON = appendJSONField(ucJSON, "Errors", codes)
+		}
+		if req := a.Requirements(d); !req.IsZero() {
+			ucJSON = appendJSONField(ucJSON, "Requires", req)
+		}
+		if timeout := a.Timeout(d); timeout > 0 {
+			ucJSON = appendJSONField(ucJSON, "Timeout", timeout.String())
+		}
+		if IsAuditable(d) {
+			ucJSON = appendJSONField(ucJSON, "Auditable", true)
+		}
 		b.WriteString(fmt.Sprintf("%q: %s", d.Operation(), ucJSON))
 	}
 
@@ -261,8 +415,39 @@ func (a *API) MarshalJSON() ([]byte, error) {
 	return []byte(b.String()), nil
 }
 
+// customConstraints lists the registered named validators referenced by the
+// input and output of d.
+func (a *API) customConstraints(d Descriptor) []string {
+	names := make([]string, 0)
+	for _, v := range []any{d.Input(), d.Output()} {
+		refl.Walk(refl.TypeOf(v), func(path string, f *refl.Field) {
+			for _, name := range f.Custom {
+				if _, ok := a.options.namedFieldValidators[name]; ok && !slices.Contains(names, name) {
+					names = append(names, name)
+				}
+			}
+		})
+	}
+	sort.Strings(names)
+	return names
+}
+
+func appendJSONField(obj []byte, key string, v any) []byte {
+	vJSON, err := json.Marshal(v)
+	if err != nil || len(obj) < 2 || obj[len(obj)-1] != '}' {
+		return obj
+	}
+	b := bytes.NewBuffer(obj[:len(obj)-1])
+	if len(obj) > 2 {
+		b.WriteString(",")
+	}
+	b.WriteString(fmt.Sprintf("%q:%s}", key, vJSON))
+	return b.Bytes()
+}
+
 type APIBuilder struct {
-	api *API
+	api        *API
+	duplicates []string
 }
 
 func NewAPIBuilder() *APIBuilder {
@@ -296,8 +481,16 @@ func (b *APIBuilder) AddErrorHook(hook ErrorHook[any]) *APIBuilder {
 	return b
 }
 
+func (b *APIBuilder) AddMiddleware(m Middleware[any, any]) *APIBuilder {
+	b.api.root.hook.AddMiddleware(m)
+	return b
+}
+
 func (b *APIBuilder) AddUseCase(d Descriptor) *APIBuilder {
 	op := d.Operation()
+	if _, ok := b.api.m[op]; ok {
+		b.duplicates = append(b.duplicates, op)
+	}
 	b.api.m[op] = d
 	return b
 }
@@ -309,6 +502,121 @@ func (b *APIBuilder) WithOptions(opts ...APIOptionFunc) *APIBuilder {
 	return b
 }
 
+// Build returns the API. It panics on problems that make a use case unusable
+// as registered, an unknown custom validator or a retry policy on an
+// operation that is not idempotent, and logs the other problems BuildE
+// reports so that APIs which built before BuildE existed keep building.
 func (b *APIBuilder) Build() *API {
+	var fatal []error
+	for _, p := range b.problems() {
+		if p.fatal {
+			fatal = append(fatal, p.err)
+			continue
+		}
+		slog.Warn("grepo: API registration problem", "error", p.err)
+	}
+	if len(fatal) > 0 {
+		panic(errors.Join(fatal...))
+	}
+	return b.build()
+}
+
+// BuildE returns the API, or an error listing every problem found in the
+// registered use cases.
+func (b *APIBuilder) BuildE() (*API, error) {
+	var errs []error
+	for _, p := range b.problems() {
+		errs = append(errs, p.err)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return b.build(), nil
+}
+
+func (b *APIBuilder) build() *API {
+	for op, d := range b.api.m {
+		b.api.plans[op] = b.api.newPlan(d)
+	}
 	return b.api
 }
+
+// A problem is a registration problem found by BuildE. Build panics only on
+// fatal ones.
+type problem struct {
+	err   error
+	fatal bool
+}
+
+func (b *APIBuilder) problems() []problem {
+	var problems []problem
+	for _, op := range b.duplicates {
+		problems = append(problems, problem{err: fmt.Errorf("%s: operation is registered more than once", op)})
+	}
+
+	type ioPair struct {
+		input  reflect.Type
+		output reflect.Type
+	}
+	pairs := make(map[ioPair]string)
+	for _, d := range b.api.UseCases() {
+		pair := ioPair{input: reflect.TypeOf(d.Input()), output: reflect.TypeOf(d.Output())}
+		if op, ok := pairs[pair]; ok {
+			problems = append(problems, problem{err: fmt.Errorf("%s: input/output types %v/%v are already used by %s", d.Operation(), pair.input, pair.output, op)})
+		} else {
+			pairs[pair] = d.Operation()
+		}
+
+		if r, ok := d.(interface{ RetryPolicy() *RetryPolicy }); ok && r.RetryPolicy() != nil && !isIdempotent(d) {
+			problems = append(problems, problem{err: fmt.Errorf("%s: retry policy requires an idempotent operation", d.Operation()), fatal: true})
+		}
+
+		problems = append(problems, b.check(d, "Input", refl.TypeOf(d.Input()))...)
+		problems = append(problems, b.check(d, "Output", refl.TypeOf(d.Output()))...)
+	}
+	return problems
+}
+
+func (b *APIBuilder) check(d Descriptor, name string, t *refl.Type) []problem {
+	var problems []problem
+	report := func(fatal bool, format string, args ...any) {
+		err := fmt.Errorf("%s: %s %s", d.Operation(), name, fmt.Sprintf(format, args...))
+		problems = append(problems, problem{err: err, fatal: fatal})
+	}
+
+	if unsupportedKind(t) {
+		report(false, "has unsupported type %s", t.Name)
+	}
+	refl.Walk(t, func(path string, f *refl.Field) {
+		if unsupportedKind(f.Type) {
+			report(false, "field %s has unsupported type %s", path, f.Type.Name)
+		}
+		if err := f.Err(); err != nil {
+			report(false, "field %s has malformed tag: %v", path, err)
+		}
+		if f.Default != "" && !f.Optional {
+			report(false, "field %s has a default but is not optional", path)
+		}
+		if len(f.Enum) > 0 && (f.Type.Kind == refl.KindObject || f.Type.Kind == refl.KindArray || f.Type.Kind == refl.KindMap) {
+			report(false, "field %s has enum constraint but is complex type", path)
+		}
+		for _, custom := range f.Custom {
+			if _, ok := b.api.options.namedFieldValidators[custom]; !ok {
+				report(true, "field %s references unknown custom validator %q", path, custom)
+			}
+		}
+	})
+	return problems
+}
+
+func unsupportedKind(t *refl.Type) bool {
+	for ; t != nil; t = t.Element {
+		if t.Kind == refl.KindUnknown {
+			return true
+		}
+		if t.Key != nil && unsupportedKind(t.Key) {
+			return true
+		}
+	}
+	return false
+}
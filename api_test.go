@@ -5,8 +5,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/ralsnet/grepo/refl"
 )
 
 // テスト用の入力・出力型
@@ -277,6 +281,8 @@ func TestAPI_WithValidation(t *testing.T) {
 	}
 }
 
+type hookCtxKey struct{}
+
 func TestAPI_WithHooks(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -456,6 +462,33 @@ func TestAPI_WithHooks(t *testing.T) {
 			wantOrder: []string{"before1", "before2", "before3", "error1", "error2"},
 			wantErr:   true,
 		},
+		{
+			name: "異常系: Beforeフックのエラーでは先行するフックのコンテキストをErrorフックに渡す",
+			setupAPI: func(t *testing.T) (*API, *[]string) {
+				order := &[]string{}
+				uc := NewUseCaseBuilder(&addOneUseCase{}).
+					WithOperation("add_one").
+					Build()
+				api := NewAPIBuilder().
+					AddUseCase(uc).
+					AddBeforeHook(func(ctx context.Context, desc Descriptor, i any) (context.Context, error) {
+						return context.WithValue(ctx, hookCtxKey{}, "opened"), nil
+					}).
+					AddBeforeHook(func(ctx context.Context, desc Descriptor, i any) (context.Context, error) {
+						return nil, fmt.Errorf("error in before hook")
+					}).
+					AddErrorHook(func(ctx context.Context, desc Descriptor, i any, e error) {
+						*order = append(*order, fmt.Sprintf("error:%v", ctx.Value(hookCtxKey{})))
+					}).
+					Build()
+				return api, order
+			},
+			operation: "add_one",
+			input:     TestInput{Value: 5},
+			want:      nil,
+			wantOrder: []string{"error:opened"},
+			wantErr:   true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -623,6 +656,18 @@ func TestUseCaseByIO(t *testing.T) {
 			want:    &TestOutput{Result: 21},
 			wantErr: false,
 		},
+		{
+			name: "正常系: 同じ入出力型ではオペレーション名が先のもの",
+			setupAPI: func() *API {
+				return NewAPIBuilder().
+					AddUseCase(NewUseCaseBuilder(&errorUseCase{}).WithOperation("z_error").Build()).
+					AddUseCase(NewUseCaseBuilder(&addOneUseCase{}).WithOperation("a_add_one").Build()).
+					Build()
+			},
+			input:   TestInput{Value: 20},
+			want:    &TestOutput{Result: 21},
+			wantErr: false,
+		},
 		{
 			name: "異常系: 該当するユースケースがない",
 			setupAPI: func() *API {
@@ -655,3 +700,690 @@ func TestUseCaseByIO(t *testing.T) {
 		})
 	}
 }
+
+type namedValidatorInput struct {
+	Slug string `json:"slug" grepo:"custom:slug,notReserved"`
+	Name string `json:"name"`
+}
+
+type namedValidatorUseCase struct{}
+
+func (u *namedValidatorUseCase) Execute(ctx context.Context, input namedValidatorInput) (*TestOutput, error) {
+	return &TestOutput{}, nil
+}
+
+func TestAPI_NamedFieldValidators(t *testing.T) {
+	var called []string
+	slug := FieldValidatorFunc(func(v reflect.Value, f *refl.Field) error {
+		called = append(called, "slug:"+f.Name)
+		if strings.ContainsAny(v.String(), " _") {
+			return errors.New("must be a slug")
+		}
+		return nil
+	})
+	notReserved := FieldValidatorFunc(func(v reflect.Value, f *refl.Field) error {
+		called = append(called, "notReserved:"+f.Name)
+		if v.String() == "admin" {
+			return errors.New("is reserved")
+		}
+		return nil
+	})
+	newBuilder := func(opts ...APIOptionFunc) *APIBuilder {
+		return NewAPIBuilder().
+			WithOptions(opts...).
+			AddUseCase(NewUseCaseBuilder(&namedValidatorUseCase{}).WithOperation("named").Build())
+	}
+
+	tests := []struct {
+		name           string
+		input          namedValidatorInput
+		wantConstraint []string
+		wantCalled     []string
+	}{
+		{
+			name:       "正常系: 指定したフィールドにのみ実行される",
+			input:      namedValidatorInput{Slug: "a-b", Name: "x y"},
+			wantCalled: []string{"slug:slug", "notReserved:slug"},
+		},
+		{
+			name:           "異常系: 名前付きバリデータの違反は名前を制約として報告する",
+			input:          namedValidatorInput{Slug: "admin", Name: "x"},
+			wantConstraint: []string{"notReserved"},
+			wantCalled:     []string{"slug:slug", "notReserved:slug"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called = nil
+			api := newBuilder(
+				WithEnableInputValidation(),
+				WithNamedFieldValidator("slug", slug),
+				WithNamedFieldValidator("notReserved", notReserved),
+			).Build()
+			_, err := api.ExecuteAny(context.Background(), "named", tt.input)
+
+			var got []string
+			var verr *ValidationError
+			if errors.As(err, &verr) {
+				for _, v := range verr.Violations {
+					got = append(got, v.Constraint)
+				}
+			} else if err != nil {
+				t.Fatalf("ExecuteAny() error = %v", err)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.wantConstraint) {
+				t.Errorf("constraints = %v, want %v", got, tt.wantConstraint)
+			}
+			if fmt.Sprint(called) != fmt.Sprint(tt.wantCalled) {
+				t.Errorf("called = %v, want %v", called, tt.wantCalled)
+			}
+		})
+	}
+
+	t.Run("異常系: 未登録の名前はBuildEでエラー", func(t *testing.T) {
+		_, err := newBuilder(WithNamedFieldValidator("slug", slug)).BuildE()
+		if err == nil || !strings.Contains(err.Error(), `"notReserved"`) {
+			t.Errorf("BuildE() error = %v, want unknown notReserved", err)
+		}
+	})
+
+	t.Run("正常系: 仕様に登録済みの制約が含まれる", func(t *testing.T) {
+		api := newBuilder(
+			WithNamedFieldValidator("slug", slug),
+			WithNamedFieldValidator("notReserved", notReserved),
+		).Build()
+		b, err := json.Marshal(api)
+		if err != nil {
+			t.Fatalf("MarshalJSON() error = %v", err)
+		}
+		var spec map[string]struct {
+			CustomConstraints []string
+		}
+		if err := json.Unmarshal(b, &spec); err != nil {
+			t.Fatalf("json.Unmarshal() error = %v", err)
+		}
+		if got := spec["named"].CustomConstraints; fmt.Sprint(got) != "[notReserved slug]" {
+			t.Errorf("CustomConstraints = %v", got)
+		}
+	})
+}
+
+// reflectDescriptor hides the typed hook chain of the wrapped Interactor so
+// that the API falls back to calling its methods through reflection.
+type reflectDescriptor struct {
+	d *Interactor[benchInput, benchOutput]
+}
+
+func (r *reflectDescriptor) Operation() string   { return r.d.Operation() }
+func (r *reflectDescriptor) Description() string { return r.d.Description() }
+func (r *reflectDescriptor) Input() any          { return r.d.Input() }
+func (r *reflectDescriptor) Output() any         { return r.d.Output() }
+func (r *reflectDescriptor) Groups() []*Group    { return r.d.Groups() }
+func (r *reflectDescriptor) Execute(ctx context.Context, input benchInput) (*benchOutput, error) {
+	return r.d.Execute(ctx, input)
+}
+func (r *reflectDescriptor) DoBeforeHook(ctx context.Context, input *benchInput) (context.Context, error) {
+	return r.d.DoBeforeHook(ctx, input)
+}
+func (r *reflectDescriptor) DoAfterHook(ctx context.Context, input benchInput, output *benchOutput) {
+	r.d.DoAfterHook(ctx, input, output)
+}
+func (r *reflectDescriptor) DoErrorHook(ctx context.Context, input benchInput, err error) {
+	r.d.DoErrorHook(ctx, input, err)
+}
+
+type benchItem struct {
+	Name  string `json:"name" grepo:"minLen:1"`
+	Count int    `json:"count" grepo:"min:0;max:100"`
+}
+
+type benchInput struct {
+	ID    string       `json:"id"`
+	Kind  string       `json:"kind" grepo:"enum:a,b"`
+	Items []*benchItem `json:"items"`
+}
+
+type benchOutput struct {
+	Items []*benchItem `json:"items"`
+}
+
+type benchUseCase struct{}
+
+func (u *benchUseCase) Execute(ctx context.Context, input benchInput) (*benchOutput, error) {
+	return &benchOutput{Items: input.Items}, nil
+}
+
+func BenchmarkAPI_ExecuteAny(b *testing.B) {
+	input := benchInput{
+		ID:   "1",
+		Kind: "a",
+		Items: []*benchItem{
+			{Name: "a", Count: 1},
+			{Name: "b", Count: 2},
+			{Name: "c", Count: 3},
+		},
+	}
+	newDescriptor := func() *Interactor[benchInput, benchOutput] {
+		return NewUseCaseBuilder(&benchUseCase{}).
+			WithOperation("bench").
+			AddBeforeHook(func(ctx context.Context, i *benchInput) (context.Context, error) {
+				return ctx, nil
+			}).
+			Build()
+	}
+
+	benchmarks := []struct {
+		name     string
+		reflect  bool
+		validate bool
+	}{
+		{name: "plan"},
+		{name: "reflect", reflect: true},
+		{name: "plan+validation", validate: true},
+		{name: "reflect+validation", reflect: true, validate: true},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			var desc Descriptor = newDescriptor()
+			if bm.reflect {
+				desc = &reflectDescriptor{d: newDescriptor()}
+			}
+			builder := NewAPIBuilder().AddUseCase(desc)
+			if bm.validate {
+				builder.WithOptions(WithEnableInputValidation(), WithEnableOutputValidation())
+			}
+			api := builder.Build()
+			if bm.reflect {
+				// Drop the precompiled plan to measure per-call reflection.
+				api.plans = map[string]*plan{}
+			}
+			ctx := context.Background()
+
+			b.ReportAllocs()
+			for b.Loop() {
+				if _, err := api.ExecuteAny(ctx, "bench", input); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+type brokenInput struct {
+	Callback func()          `json:"callback"`
+	Values   []chan int      `json:"values"`
+	Count    int             `json:"count" grepo:"min:x"`
+	Nested   *validatedInput `json:"nested" grepo:"enum:a,b"`
+	Named    string          `json:"named" grepo:"custom:missing"`
+}
+
+type brokenUseCase struct{}
+
+func (u *brokenUseCase) Execute(ctx context.Context, input brokenInput) (*TestOutput, error) {
+	return &TestOutput{}, nil
+}
+
+type treeNode struct {
+	Name     string      `json:"name"`
+	Children []*treeNode `json:"children" grepo:"optional"`
+}
+
+type treeUseCase struct{}
+
+func (u *treeUseCase) Execute(ctx context.Context, input treeNode) (*treeNode, error) {
+	return &input, nil
+}
+
+func TestAPIBuilder_BuildE(t *testing.T) {
+	tests := []struct {
+		name      string
+		builder   func() *APIBuilder
+		wantErrs  []string
+		wantPanic bool
+	}{
+		{
+			name: "正常系: 問題なし",
+			builder: func() *APIBuilder {
+				return NewAPIBuilder().
+					AddUseCase(NewUseCaseBuilder(&addOneUseCase{}).WithOperation("add_one").Build())
+			},
+		},
+		{
+			name: "異常系: 入出力型の組の重複",
+			builder: func() *APIBuilder {
+				return NewAPIBuilder().
+					AddUseCase(NewUseCaseBuilder(&addOneUseCase{}).WithOperation("add_one").Build()).
+					AddUseCase(NewUseCaseBuilder(&errorUseCase{}).WithOperation("error").Build())
+			},
+			wantErrs: []string{
+				"error: input/output types grepo.TestInput/grepo.TestOutput are already used by add_one",
+			},
+		},
+		{
+			name: "正常系: 再帰的な入出力型",
+			builder: func() *APIBuilder {
+				return NewAPIBuilder().
+					AddUseCase(NewUseCaseBuilder(&treeUseCase{}).WithOperation("tree").Build())
+			},
+		},
+		{
+			name: "異常系: オペレーション名の重複",
+			builder: func() *APIBuilder {
+				return NewAPIBuilder().
+					AddUseCase(NewUseCaseBuilder(&addOneUseCase{}).WithOperation("add_one").Build()).
+					AddUseCase(NewUseCaseBuilder(&validatedUseCase{}).WithOperation("add_one").Build())
+			},
+			wantErrs: []string{
+				"add_one: operation is registered more than once",
+			},
+		},
+		{
+			name: "異常系: 型とタグの問題を全て報告する",
+			builder: func() *APIBuilder {
+				return NewAPIBuilder().
+					AddUseCase(NewUseCaseBuilder(&brokenUseCase{}).WithOperation("broken").Build())
+			},
+			wantErrs: []string{
+				"broken: Input field callback has unsupported type func()",
+				"broken: Input field values has unsupported type []chan int",
+				`broken: Input field count has malformed tag: grepo tag "min:x": strconv.ParseFloat: parsing "x": invalid syntax`,
+				"broken: Input field nested has enum constraint but is complex type",
+				`broken: Input field named references unknown custom validator "missing"`,
+			},
+			wantPanic: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api, err := tt.builder().BuildE()
+			if len(tt.wantErrs) == 0 {
+				if err != nil || api == nil {
+					t.Errorf("BuildE() = %v, %v", api, err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("BuildE() error = nil")
+			}
+			if got := strings.Split(err.Error(), "\n"); fmt.Sprintf("%q", got) != fmt.Sprintf("%q", tt.wantErrs) {
+				t.Errorf("BuildE() error =\n%s\nwant\n%s", err, strings.Join(tt.wantErrs, "\n"))
+			}
+
+			defer func() {
+				if r := recover(); (r != nil) != tt.wantPanic {
+					t.Errorf("Build() panic = %v, wantPanic %v", r, tt.wantPanic)
+				}
+			}()
+			tt.builder().Build()
+		})
+	}
+}
+
+type legacyInput struct {
+	Value   int            `json:"value" grepo:"min:x"`
+	Payload any            `json:"payload"`
+	Extra   map[string]any `json:"extra"`
+	Nested  TestInput      `json:"nested" grepo:"enum:a,b"`
+}
+
+type legacyUseCase struct{}
+
+func (u *legacyUseCase) Execute(ctx context.Context, input legacyInput) (*TestOutput, error) {
+	return &TestOutput{Result: input.Value + 1}, nil
+}
+
+func TestAPIBuilder_Build_Compatible(t *testing.T) {
+	api := NewAPIBuilder().
+		AddUseCase(NewUseCaseBuilder(&addOneUseCase{}).WithOperation("legacy").Build()).
+		AddUseCase(NewUseCaseBuilder(&legacyUseCase{}).WithOperation("legacy").Build()).
+		Build()
+
+	input := legacyInput{Value: 1, Payload: []any{"a", 1}, Extra: map[string]any{"k": true}}
+	out, err := UseCase[legacyInput, TestOutput](api, "legacy").Execute(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if out.Result != 2 {
+		t.Errorf("Execute() = %v, want 2", out.Result)
+	}
+}
+
+type panicUseCase struct{}
+
+func (u *panicUseCase) Execute(ctx context.Context, input TestInput) (*TestOutput, error) {
+	if input.Value < 0 {
+		panic(errors.New("negative value"))
+	}
+	var m map[string]int
+	m["value"] = input.Value
+	return &TestOutput{}, nil
+}
+
+func TestAPI_WithPanicRecovery(t *testing.T) {
+	newAPI := func(order *[]string, opts ...APIOptionFunc) *API {
+		return NewAPIBuilder().
+			WithOptions(opts...).
+			AddErrorHook(func(ctx context.Context, desc Descriptor, i any, err error) {
+				*order = append(*order, "root:"+string(CodeOf(err)))
+			}).
+			AddUseCase(NewUseCaseBuilder(&panicUseCase{}).
+				WithOperation("panic").
+				AddErrorHook(func(ctx context.Context, i TestInput, err error) {
+					*order = append(*order, "usecase:"+string(CodeOf(err)))
+				}).
+				Build()).
+			AddUseCase(NewUseCaseBuilder(&addOneUseCase{}).
+				WithOperation("after_panic").
+				AddAfterHook(func(ctx context.Context, i TestInput, o *TestOutput) {
+					panic("after hook")
+				}).
+				Build()).
+			Build()
+	}
+
+	t.Run("正常系: panicをInternalエラーとしてエラーフックに渡す", func(t *testing.T) {
+		order := []string{}
+		out, err := newAPI(&order, WithPanicRecovery()).ExecuteAny(context.Background(), "panic", TestInput{Value: 1})
+		if out != nil {
+			t.Errorf("ExecuteAny() output = %v, want nil", out)
+		}
+		if !errors.Is(err, ErrInternal) {
+			t.Fatalf("ExecuteAny() error = %v, want ErrInternal", err)
+		}
+		var perr *PanicError
+		if !errors.As(err, &perr) {
+			t.Fatalf("ExecuteAny() error = %v, want PanicError cause", err)
+		}
+		if !strings.Contains(string(perr.Stack), "panicUseCase") {
+			t.Errorf("Stack does not contain the panicking frame:\n%s", perr.Stack)
+		}
+		if got, want := fmt.Sprint(order), "[root:Internal usecase:Internal]"; got != want {
+			t.Errorf("order = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("正常系: errorのpanic値を辿れる", func(t *testing.T) {
+		order := []string{}
+		_, err := newAPI(&order, WithPanicRecovery()).ExecuteAny(context.Background(), "panic", TestInput{Value: -1})
+		if err == nil || !strings.Contains(err.Error(), "negative value") {
+			t.Errorf("ExecuteAny() error = %v, want negative value", err)
+		}
+	})
+
+	t.Run("正常系: Afterフックのpanicも回復する", func(t *testing.T) {
+		order := []string{}
+		_, err := newAPI(&order, WithPanicRecovery()).ExecuteAny(context.Background(), "after_panic", TestInput{Value: 1})
+		if CodeOf(err) != CodeInternal {
+			t.Errorf("ExecuteAny() error = %v, want Internal", err)
+		}
+		if got, want := fmt.Sprint(order), "[root:Internal]"; got != want {
+			t.Errorf("order = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("異常系: オプションなしではpanicが伝播する", func(t *testing.T) {
+		order := []string{}
+		defer func() {
+			if recover() == nil {
+				t.Error("ExecuteAny() did not panic")
+			}
+			if len(order) != 0 {
+				t.Errorf("order = %v, want no error hooks", order)
+			}
+		}()
+		newAPI(&order).ExecuteAny(context.Background(), "panic", TestInput{Value: 1})
+	})
+}
+
+func TestAPI_WithMiddleware(t *testing.T) {
+	trace := func(order *[]string, name string) Middleware[any, any] {
+		return func(ctx context.Context, desc Descriptor, i any, next Next[any, any]) (any, error) {
+			*order = append(*order, name+":in")
+			o, err := next(ctx, i)
+			*order = append(*order, name+":out")
+			return o, err
+		}
+	}
+
+	t.Run("正常系: Root→Group→UseCaseの順にラップする", func(t *testing.T) {
+		order := []string{}
+		group := NewGroup("group").AddMiddleware(trace(&order, "group"))
+		api := NewAPIBuilder().
+			AddBeforeHook(func(ctx context.Context, desc Descriptor, i any) (context.Context, error) {
+				order = append(order, "before")
+				return ctx, nil
+			}).
+			AddAfterHook(func(ctx context.Context, desc Descriptor, i any, o any) {
+				order = append(order, "after")
+			}).
+			AddMiddleware(trace(&order, "root")).
+			AddUseCase(NewUseCaseBuilder(&addOneUseCase{}).
+				WithOperation("add_one").
+				WithGroup(group).
+				AddMiddleware(func(ctx context.Context, i TestInput, next Next[TestInput, *TestOutput]) (*TestOutput, error) {
+					order = append(order, "usecase:in")
+					i.Value *= 10
+					o, err := next(ctx, i)
+					order = append(order, "usecase:out")
+					return o, err
+				}).
+				Build()).
+			Build()
+
+		out, err := api.ExecuteAny(context.Background(), "add_one", TestInput{Value: 1})
+		if err != nil {
+			t.Fatalf("ExecuteAny() error = %v", err)
+		}
+		if got := out.(*TestOutput).Result; got != 11 {
+			t.Errorf("Result = %v, want 11", got)
+		}
+		want := "[before root:in group:in usecase:in usecase:out group:out root:out after]"
+		if got := fmt.Sprint(order); got != want {
+			t.Errorf("order = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("正常系: nextを呼ばずに出力を返す", func(t *testing.T) {
+		called := false
+		api := NewAPIBuilder().
+			AddUseCase(NewUseCaseBuilder(ExecutorFunc[TestInput, TestOutput](func(ctx context.Context, i TestInput) (*TestOutput, error) {
+				called = true
+				return &TestOutput{}, nil
+			})).
+				WithOperation("cached").
+				AddMiddleware(func(ctx context.Context, i TestInput, next Next[TestInput, *TestOutput]) (*TestOutput, error) {
+					return &TestOutput{Result: 42}, nil
+				}).
+				Build()).
+			Build()
+
+		out, err := api.ExecuteAny(context.Background(), "cached", TestInput{})
+		if err != nil || out.(*TestOutput).Result != 42 || called {
+			t.Errorf("ExecuteAny() = %v, %v (called = %v)", out, err, called)
+		}
+	})
+
+	t.Run("正常系: nextを再度呼び出せる", func(t *testing.T) {
+		attempts := 0
+		api := NewAPIBuilder().
+			AddMiddleware(func(ctx context.Context, desc Descriptor, i any, next Next[any, any]) (any, error) {
+				o, err := next(ctx, i)
+				if err != nil {
+					return next(ctx, i)
+				}
+				return o, err
+			}).
+			AddUseCase(NewUseCaseBuilder(ExecutorFunc[TestInput, TestOutput](func(ctx context.Context, i TestInput) (*TestOutput, error) {
+				attempts++
+				if attempts == 1 {
+					return nil, ErrUnavailable
+				}
+				return &TestOutput{Result: attempts}, nil
+			})).WithOperation("flaky").Build()).
+			Build()
+
+		out, err := api.ExecuteAny(context.Background(), "flaky", TestInput{})
+		if err != nil || out.(*TestOutput).Result != 2 {
+			t.Errorf("ExecuteAny() = %v, %v", out, err)
+		}
+	})
+}
+
+func TestAPI_WithTimeout(t *testing.T) {
+	slow := ExecutorFunc[TestInput, TestOutput](func(ctx context.Context, i TestInput) (*TestOutput, error) {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Second):
+			return &TestOutput{}, nil
+		}
+	})
+
+	tests := []struct {
+		name        string
+		option      APIOptionFunc
+		group       *Group
+		timeout     time.Duration
+		wantTimeout string
+	}{
+		{name: "UseCaseのタイムアウト", group: NewGroup("g").WithTimeout(time.Hour), timeout: 10 * time.Millisecond, wantTimeout: "10ms"},
+		{name: "Groupのタイムアウト", group: NewGroup("g").WithTimeout(20 * time.Millisecond), option: WithDefaultTimeout(time.Hour), wantTimeout: "20ms"},
+		{name: "APIのデフォルト", group: NewGroup("g"), option: WithDefaultTimeout(30 * time.Millisecond), wantTimeout: "30ms"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var hooked error
+			b := NewAPIBuilder().
+				AddErrorHook(func(ctx context.Context, desc Descriptor, i any, err error) {
+					hooked = err
+				}).
+				AddUseCase(NewUseCaseBuilder(slow).WithOperation("slow").WithGroup(tt.group).WithTimeout(tt.timeout).Build())
+			if tt.option != nil {
+				b = b.WithOptions(tt.option)
+			}
+			api := b.Build()
+
+			_, err := api.ExecuteAny(context.Background(), "slow", TestInput{})
+			var gerr *Error
+			if !errors.As(err, &gerr) || gerr.Code != CodeDeadlineExceeded {
+				t.Fatalf("ExecuteAny() error = %v, want DeadlineExceeded", err)
+			}
+			if !errors.Is(err, ErrDeadlineExceeded) || !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("errors.Is() = false for %v", err)
+			}
+			if got := gerr.Details["timeout"]; got != tt.wantTimeout {
+				t.Errorf("Details[timeout] = %v, want %v", got, tt.wantTimeout)
+			}
+			if hooked != err {
+				t.Errorf("error hook got %v, want %v", hooked, err)
+			}
+
+			b2, _ := json.Marshal(api)
+			var spec map[string]struct {
+				Errors  []Code
+				Timeout string
+			}
+			if err := json.Unmarshal(b2, &spec); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			if got := spec["slow"]; got.Timeout != tt.wantTimeout || fmt.Sprint(got.Errors) != "[DeadlineExceeded]" {
+				t.Errorf("spec = %+v", got)
+			}
+		})
+	}
+
+	t.Run("正常系: タイムアウトなし", func(t *testing.T) {
+		api := NewAPIBuilder().
+			AddUseCase(NewUseCaseBuilder(&addOneUseCase{}).WithOperation("add_one").Build()).
+			Build()
+		if got := api.Timeout(api.UseCases()[0]); got != 0 {
+			t.Errorf("Timeout() = %v, want 0", got)
+		}
+		if _, err := api.ExecuteAny(context.Background(), "add_one", TestInput{}); err != nil {
+			t.Errorf("ExecuteAny() error = %v", err)
+		}
+	})
+}
+
+type nestedUseCase struct {
+	api   func() *API
+	infos *[]*ExecutionInfo
+}
+
+func (u *nestedUseCase) Execute(ctx context.Context, input TestInput) (*TestOutput, error) {
+	*u.infos = append(*u.infos, ExecutionInfoFrom(ctx))
+	if _, err := u.api().ExecuteAny(ctx, "add_one", input); err != nil {
+		return nil, err
+	}
+	return &TestOutput{}, nil
+}
+
+func TestAPI_ExecutionInfo(t *testing.T) {
+	infos := []*ExecutionInfo{}
+	ids := 0
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var api *API
+	api = NewAPIBuilder().
+		WithOptions(WithClock(NewFakeClock(now)), WithIDGenerator(func() string {
+			ids++
+			return fmt.Sprintf("id-%d", ids)
+		})).
+		AddUseCase(NewUseCaseBuilder(&nestedUseCase{api: func() *API { return api }, infos: &infos}).WithOperation("nested").Build()).
+		AddUseCase(NewUseCaseBuilder(&addOneUseCase{}).
+			WithOperation("add_one").
+			AddBeforeHook(func(ctx context.Context, i *TestInput) (context.Context, error) {
+				infos = append(infos, ExecutionInfoFrom(ctx))
+				return ctx, nil
+			}).
+			Build()).
+		Build()
+
+	principal := &Principal{ID: "user-1", Roles: []string{"admin"}}
+	ctx := WithPrincipal(WithTransport(context.Background(), TransportTest), principal)
+	if ExecutionInfoFrom(ctx) != nil {
+		t.Fatal("ExecutionInfoFrom() outside of an execution is not nil")
+	}
+	if _, ok := Elapsed(ctx); ok {
+		t.Fatal("Elapsed() outside of an execution reports true")
+	}
+	if _, err := api.ExecuteAny(ctx, "nested", TestInput{}); err != nil {
+		t.Fatalf("ExecuteAny() error = %v", err)
+	}
+
+	want := []ExecutionInfo{
+		{ID: "id-1", Operation: "nested", Transport: TransportTest, Principal: principal, StartTime: now},
+		{ID: "id-2", ParentID: "id-1", Operation: "add_one", Transport: TransportTest, Principal: principal, StartTime: now},
+	}
+	if len(infos) != len(want) {
+		t.Fatalf("infos = %v", infos)
+	}
+	for i, info := range infos {
+		if !reflect.DeepEqual(*info, want[i]) {
+			t.Errorf("infos[%d] = %+v, want %+v", i, *info, want[i])
+		}
+	}
+}
+
+func TestAPI_ExecutionInfo_DefaultID(t *testing.T) {
+	var ids []string
+	api := NewAPIBuilder().
+		AddUseCase(NewUseCaseBuilder(&addOneUseCase{}).
+			WithOperation("add_one").
+			AddBeforeHook(func(ctx context.Context, i *TestInput) (context.Context, error) {
+				ids = append(ids, ExecutionInfoFrom(ctx).ID)
+				return ctx, nil
+			}).
+			Build()).
+		Build()
+	for range 2 {
+		if _, err := api.ExecuteAny(context.Background(), "add_one", TestInput{}); err != nil {
+			t.Fatalf("ExecuteAny() error = %v", err)
+		}
+	}
+	if len(ids[0]) != 32 || ids[0] == ids[1] {
+		t.Errorf("IDs = %v, want distinct 32 digit IDs", ids)
+	}
+}
@@ -0,0 +1,134 @@
+package audit
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/ralsnet/grepo"
+	"github.com/ralsnet/grepo/hooks"
+)
+
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+)
+
+// Record describes one execution of an auditable use case.
+type Record struct {
+	ExecutionID string           `json:"executionId"`
+	ParentID    string           `json:"parentId,omitempty"`
+	Operation   string           `json:"operation"`
+	Groups      []string         `json:"groups"`
+	Transport   string           `json:"transport,omitempty"`
+	Principal   *grepo.Principal `json:"principal,omitempty"`
+	Input       any              `json:"input"`
+	Outcome     string           `json:"outcome"`
+	ErrorCode   grepo.Code       `json:"errorCode,omitempty"`
+	ExecuteTime time.Time        `json:"executeTime"`
+	// Duration is measured from grepo.ExecutionInfo.StartTime.
+	Duration time.Duration `json:"duration"`
+}
+
+// Sink stores audit records. Implementations must be safe for concurrent use.
+type Sink interface {
+	Write(ctx context.Context, r *Record) error
+}
+
+type SinkFunc func(ctx context.Context, r *Record) error
+
+func (f SinkFunc) Write(ctx context.Context, r *Record) error {
+	return f(ctx, r)
+}
+
+type Options struct {
+	redactor *hooks.Redactor
+	onError  func(ctx context.Context, r *Record, err error)
+}
+
+type OptionFunc func(*Options)
+
+// WithRedactor renders inputs with redactor instead of a hooks.Redactor
+// without depth limit. Fields tagged grepo:"sensitive" are always redacted.
+func WithRedactor(redactor *hooks.Redactor) OptionFunc {
+	return func(o *Options) {
+		o.redactor = redactor
+	}
+}
+
+// WithErrorHandler is called when the sink fails to store a record. By
+// default the failure is logged with slog.Default().
+func WithErrorHandler(fn func(ctx context.Context, r *Record, err error)) OptionFunc {
+	return func(o *Options) {
+		o.onError = fn
+	}
+}
+
+// HookAfter writes a record of each successful execution of an auditable use
+// case to sink.
+func HookAfter(sink Sink, opts ...OptionFunc) grepo.AfterHook[any, any] {
+	options := newOptions(opts)
+	return func(ctx context.Context, desc grepo.Descriptor, i any, o any) {
+		if !grepo.IsAuditable(desc) {
+			return
+		}
+		options.write(ctx, sink, options.record(ctx, desc, i, nil))
+	}
+}
+
+// HookError writes a record of each failed execution of an auditable use case
+// to sink, including those denied by the authorizer.
+func HookError(sink Sink, opts ...OptionFunc) grepo.ErrorHook[any] {
+	options := newOptions(opts)
+	return func(ctx context.Context, desc grepo.Descriptor, i any, err error) {
+		if !grepo.IsAuditable(desc) {
+			return
+		}
+		options.write(ctx, sink, options.record(ctx, desc, i, err))
+	}
+}
+
+func newOptions(opts []OptionFunc) *Options {
+	options := &Options{
+		redactor: &hooks.Redactor{},
+		onError: func(ctx context.Context, r *Record, err error) {
+			slog.ErrorContext(ctx, "Failed to write audit record", "operation", r.Operation, "execution_id", r.ExecutionID, "error", err)
+		},
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+func (o *Options) record(ctx context.Context, desc grepo.Descriptor, i any, err error) *Record {
+	r := &Record{
+		Operation:   desc.Operation(),
+		Groups:      make([]string, 0, len(desc.Groups())),
+		Input:       o.redactor.Redact(i),
+		Outcome:     OutcomeSuccess,
+		ExecuteTime: grepo.ExecuteTime(ctx),
+	}
+	for _, g := range desc.Groups() {
+		r.Groups = append(r.Groups, g.Name())
+	}
+	info := grepo.ExecutionInfoFrom(ctx)
+	if info != nil {
+		r.ExecutionID = info.ID
+		r.ParentID = info.ParentID
+		r.Transport = info.Transport
+		r.Principal = info.Principal
+	}
+	if err != nil {
+		r.Outcome = OutcomeError
+		r.ErrorCode = grepo.CodeOf(err)
+	}
+	r.Duration, _ = grepo.Elapsed(ctx)
+	return r
+}
+
+func (o *Options) write(ctx context.Context, sink Sink, r *Record) {
+	if err := sink.Write(ctx, r); err != nil {
+		o.onError(ctx, r, err)
+	}
+}
@@ -0,0 +1,165 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ralsnet/grepo"
+)
+
+type saveInput struct {
+	Name     string
+	Password string `grepo:"sensitive"`
+}
+
+type output struct{}
+
+type saveUseCase struct{}
+
+func (u *saveUseCase) Execute(ctx context.Context, in saveInput) (*output, error) {
+	grepo.ClockFrom(ctx).(*grepo.FakeClock).Advance(2 * time.Second)
+	if in.Name == "" {
+		return nil, grepo.NewError(grepo.CodeConflict, "conflict")
+	}
+	return &output{}, nil
+}
+
+type listUseCase struct{}
+
+func (u *listUseCase) Execute(ctx context.Context, in saveInput) (*output, error) {
+	return &output{}, nil
+}
+
+type deleteUseCase struct{}
+
+func (u *deleteUseCase) Execute(ctx context.Context, in saveInput) (*output, error) {
+	return &output{}, nil
+}
+
+type memorySink struct {
+	mu      sync.Mutex
+	records []*Record
+}
+
+func (s *memorySink) Write(ctx context.Context, r *Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, r)
+	return nil
+}
+
+func TestHooks(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	sink := &memorySink{}
+	admin := grepo.NewGroup("admin").WithAuditable().WithRoles("admin")
+	api := grepo.NewAPIBuilder().
+		WithOptions(
+			grepo.WithClock(grepo.NewFakeClock(now)),
+			grepo.WithIDGenerator(func() string { return "exec-1" }),
+		).
+		AddAfterHook(HookAfter(sink)).
+		AddErrorHook(HookError(sink)).
+		AddUseCase(grepo.NewUseCaseBuilder(&saveUseCase{}).WithOperation("save").WithAuditable().Build()).
+		AddUseCase(grepo.NewUseCaseBuilder(&listUseCase{}).WithOperation("list").Build()).
+		AddUseCase(grepo.NewUseCaseBuilder(&deleteUseCase{}).WithOperation("delete").WithGroup(admin).Build()).
+		Build()
+
+	principal := &grepo.Principal{ID: "alice", Roles: []string{"staff"}}
+	ctx := grepo.WithPrincipal(grepo.WithTransport(context.Background(), grepo.TransportTest), principal)
+
+	tests := []struct {
+		name      string
+		operation string
+		input     saveInput
+		want      *Record
+	}{
+		{
+			name:      "正常系: 成功を記録",
+			operation: "save",
+			input:     saveInput{Name: "bob", Password: "secret"},
+			want: &Record{
+				ExecutionID: "exec-1", Operation: "save", Groups: []string{}, Transport: grepo.TransportTest, Principal: principal,
+				Input: map[string]any{"Name": "bob", "Password": "[REDACTED]"}, Outcome: OutcomeSuccess, ExecuteTime: now, Duration: 2 * time.Second,
+			},
+		},
+		{
+			name:      "異常系: エラーコードを記録",
+			operation: "save",
+			input:     saveInput{Password: "secret"},
+			want: &Record{
+				ExecutionID: "exec-1", Operation: "save", Groups: []string{}, Transport: grepo.TransportTest, Principal: principal,
+				Input: map[string]any{"Name": "", "Password": "[REDACTED]"}, Outcome: OutcomeError, ErrorCode: grepo.CodeConflict,
+				ExecuteTime: now.Add(2 * time.Second), Duration: 2 * time.Second,
+			},
+		},
+		{
+			name:      "異常系: グループで指定した監査対象の認可エラー",
+			operation: "delete",
+			input:     saveInput{Name: "bob"},
+			want: &Record{
+				ExecutionID: "exec-1", Operation: "delete", Groups: []string{"admin"}, Transport: grepo.TransportTest, Principal: principal,
+				Input: map[string]any{"Name": "bob", "Password": "[REDACTED]"}, Outcome: OutcomeError, ErrorCode: grepo.CodePermissionDenied,
+				ExecuteTime: now.Add(4 * time.Second),
+			},
+		},
+		{
+			name:      "正常系: 監査対象外",
+			operation: "list",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink.records = nil
+			_, _ = api.ExecuteAny(ctx, tt.operation, tt.input)
+			if tt.want == nil {
+				if len(sink.records) != 0 {
+					t.Errorf("records = %+v", sink.records)
+				}
+				return
+			}
+			if len(sink.records) != 1 {
+				t.Fatalf("records = %+v", sink.records)
+			}
+			got, _ := json.Marshal(sink.records[0])
+			want, _ := json.Marshal(tt.want)
+			if string(got) != string(want) {
+				t.Errorf("record = %s, want %s", got, want)
+			}
+		})
+	}
+
+	t.Run("正常系: API仕様", func(t *testing.T) {
+		var spec map[string]struct{ Auditable bool }
+		b, _ := json.Marshal(api)
+		if err := json.Unmarshal(b, &spec); err != nil {
+			t.Fatal(err)
+		}
+		if !spec["save"].Auditable || !spec["delete"].Auditable || spec["list"].Auditable {
+			t.Errorf("spec = %+v", spec)
+		}
+	})
+}
+
+func TestHooks_ErrorHandler(t *testing.T) {
+	var handled error
+	sink := SinkFunc(func(ctx context.Context, r *Record) error {
+		return errors.New("disk full")
+	})
+	api := grepo.NewAPIBuilder().
+		AddAfterHook(HookAfter(sink, WithErrorHandler(func(ctx context.Context, r *Record, err error) {
+			handled = err
+		}))).
+		AddUseCase(grepo.NewUseCaseBuilder(&listUseCase{}).WithOperation("list").WithAuditable().Build()).
+		Build()
+
+	if _, err := api.ExecuteAny(context.Background(), "list", saveInput{Name: "bob"}); err != nil {
+		t.Fatalf("ExecuteAny() error = %v", err)
+	}
+	if handled == nil || handled.Error() != "disk full" {
+		t.Errorf("handled = %v", handled)
+	}
+}
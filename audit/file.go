@@ -0,0 +1,208 @@
+package audit
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ralsnet/grepo"
+)
+
+// DefaultMaxSize is the size in bytes above which a FileSink rotates.
+const DefaultMaxSize = 100 << 20
+
+const backupTimeFormat = "20060102T150405.000000000"
+
+var ErrClosed = errors.New("audit: sink closed")
+
+type FileSinkOptions struct {
+	maxSize    int64
+	maxBackups int
+	clock      grepo.Clock
+}
+
+type FileSinkOptionFunc func(*FileSinkOptions)
+
+// WithMaxSize rotates the file before a record would grow it beyond size
+// bytes. Zero disables rotation.
+func WithMaxSize(size int64) FileSinkOptionFunc {
+	return func(o *FileSinkOptions) {
+		o.maxSize = size
+	}
+}
+
+// WithMaxBackups removes the oldest rotated files beyond n. Zero keeps them
+// all.
+func WithMaxBackups(n int) FileSinkOptionFunc {
+	return func(o *FileSinkOptions) {
+		o.maxBackups = n
+	}
+}
+
+// WithClock names rotated files after the time of c instead of the real
+// clock.
+func WithClock(c grepo.Clock) FileSinkOptionFunc {
+	return func(o *FileSinkOptions) {
+		o.clock = c
+	}
+}
+
+// FileSink appends records to a file as JSON Lines. When the file reaches its
+// maximum size it is renamed after the time of rotation, as in
+// "audit-20250101T000000.000000000.jsonl" for "audit.jsonl", and a new file is
+// started. Rotated files are never written again. When renaming or pruning
+// fails, Write still appends the record to the active file and returns the
+// error.
+type FileSink struct {
+	options FileSinkOptions
+	path    string
+	mu      sync.Mutex
+	file    *os.File
+	size    int64
+}
+
+func NewFileSink(path string, opts ...FileSinkOptionFunc) (*FileSink, error) {
+	options := FileSinkOptions{
+		maxSize: DefaultMaxSize,
+		clock:   grepo.RealClock(),
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	s := &FileSink{options: options, path: path}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *FileSink) Write(ctx context.Context, r *Record) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return ErrClosed
+	}
+	var rotateErr error
+	if s.options.maxSize > 0 && s.size > 0 && s.size+int64(len(b)) > s.options.maxSize {
+		// A failed rotation is reported, but the record still goes to the
+		// active file so that it is not lost.
+		rotateErr = s.rotate(s.options.clock.Now())
+		if s.file == nil {
+			return rotateErr
+		}
+	}
+	n, err := s.file.Write(b)
+	s.size += int64(n)
+	return errors.Join(rotateErr, err)
+}
+
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+func (s *FileSink) open() error {
+	f, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	s.file = f
+	s.size = info.Size()
+	return nil
+}
+
+// rotate renames the active file to a backup and reopens the path. The path
+// is reopened whatever fails before, so a failed rename or prune leaves the
+// sink writing to the active file instead of closing it.
+func (s *FileSink) rotate(now time.Time) error {
+	err := s.file.Close()
+	s.file = nil
+	if err == nil {
+		err = s.backup(now)
+	}
+	return errors.Join(err, s.open())
+}
+
+func (s *FileSink) backup(now time.Time) error {
+	dir, base, ext := s.split()
+	var backup string
+	for {
+		backup = filepath.Join(dir, base+"-"+now.UTC().Format(backupTimeFormat)+ext)
+		if _, err := os.Stat(backup); errors.Is(err, os.ErrNotExist) {
+			break
+		}
+		// Keep the names unique and in rotation order under a coarse or fake
+		// clock.
+		now = now.Add(time.Nanosecond)
+	}
+	if err := os.Rename(s.path, backup); err != nil {
+		return err
+	}
+	return s.prune()
+}
+
+// split returns the directory of the file and its name without and with only
+// the extension.
+func (s *FileSink) split() (dir, base, ext string) {
+	dir, name := filepath.Split(s.path)
+	ext = filepath.Ext(name)
+	return dir, strings.TrimSuffix(name, ext), ext
+}
+
+// prune removes the oldest backups beyond the configured maximum. Backup names
+// sort in the order they were rotated.
+func (s *FileSink) prune() error {
+	if s.options.maxBackups <= 0 {
+		return nil
+	}
+	dir, base, ext := s.split()
+	entries, err := os.ReadDir(cmp.Or(dir, "."))
+	if err != nil {
+		return err
+	}
+	var backups []string
+	for _, e := range entries {
+		stamp, ok := strings.CutPrefix(e.Name(), base+"-")
+		if !ok {
+			continue
+		}
+		stamp, ok = strings.CutSuffix(stamp, ext)
+		if _, err := time.Parse(backupTimeFormat, stamp); !ok || err != nil {
+			continue
+		}
+		backups = append(backups, e.Name())
+	}
+	if len(backups) <= s.options.maxBackups {
+		return nil
+	}
+	slices.Sort(backups)
+	var errs []error
+	for _, b := range backups[:len(backups)-s.options.maxBackups] {
+		errs = append(errs, os.Remove(filepath.Join(dir, b)))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,144 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ralsnet/grepo"
+)
+
+func TestFileSink(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "audit.jsonl")
+	if err := os.WriteFile(filepath.Join(dir, "audit-other.jsonl"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	clock := grepo.NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	ctx := context.Background()
+
+	line, _ := json.Marshal(&Record{ExecutionID: "exec-0"})
+	sink, err := NewFileSink(path, WithMaxSize(int64(2*len(line)+2)), WithMaxBackups(2), WithClock(clock))
+	if err != nil {
+		t.Fatalf("NewFileSink() error = %v", err)
+	}
+
+	for i := range 7 {
+		if err := sink.Write(ctx, &Record{ExecutionID: "exec-" + string(rune('0'+i))}); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		clock.Advance(time.Second)
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if err := sink.Write(ctx, &Record{}); err != ErrClosed {
+		t.Errorf("Write() after Close() error = %v, want ErrClosed", err)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	want := []string{"audit-20250101T000004.000000000.jsonl", "audit-20250101T000006.000000000.jsonl", "audit-other.jsonl", "audit.jsonl"}
+	if !slices.Equal(names, want) {
+		t.Fatalf("files = %v, want %v", names, want)
+	}
+
+	for name, ids := range map[string][]string{
+		"audit-20250101T000004.000000000.jsonl": {"exec-2", "exec-3"},
+		"audit-20250101T000006.000000000.jsonl": {"exec-4", "exec-5"},
+		"audit.jsonl":                           {"exec-6"},
+	} {
+		if got := executionIDs(t, filepath.Join(dir, name)); !slices.Equal(got, ids) {
+			t.Errorf("%s = %v, want %v", name, got, ids)
+		}
+	}
+
+	t.Run("正常系: 既存ファイルへの追記", func(t *testing.T) {
+		sink, err := NewFileSink(path)
+		if err != nil {
+			t.Fatalf("NewFileSink() error = %v", err)
+		}
+		defer sink.Close()
+		if err := sink.Write(ctx, &Record{ExecutionID: "exec-7"}); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		if got := executionIDs(t, path); !slices.Equal(got, []string{"exec-6", "exec-7"}) {
+			t.Errorf("audit.jsonl = %v", got)
+		}
+	})
+}
+
+func TestFileSink_PruneError(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "audit.jsonl")
+	// A non-empty directory named like the oldest backup cannot be removed.
+	stale := filepath.Join(dir, "audit-20000101T000000.000000000.jsonl")
+	if err := os.MkdirAll(filepath.Join(stale, "keep"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	clock := grepo.NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	ctx := context.Background()
+
+	line, _ := json.Marshal(&Record{ExecutionID: "exec-0"})
+	sink, err := NewFileSink(path, WithMaxSize(int64(len(line)+1)), WithMaxBackups(1), WithClock(clock))
+	if err != nil {
+		t.Fatalf("NewFileSink() error = %v", err)
+	}
+	defer sink.Close()
+
+	if err := sink.Write(ctx, &Record{ExecutionID: "exec-0"}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	clock.Advance(time.Second)
+	if err := sink.Write(ctx, &Record{ExecutionID: "exec-1"}); err == nil {
+		t.Error("Write() rotating with a failing prune error = nil, want error")
+	}
+	if err := sink.Write(ctx, &Record{ExecutionID: "exec-2"}); err == nil {
+		t.Error("Write() rotating with a failing prune error = nil, want error")
+	}
+
+	if got := executionIDs(t, path); !slices.Equal(got, []string{"exec-2"}) {
+		t.Errorf("audit.jsonl = %v, want [exec-2]", got)
+	}
+	entries, _ := os.ReadDir(dir)
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	want := []string{filepath.Base(stale), "audit-20250101T000001.000000001.jsonl", "audit.jsonl"}
+	if !slices.Equal(names, want) {
+		t.Fatalf("files = %v, want %v", names, want)
+	}
+	if got := executionIDs(t, filepath.Join(dir, want[1])); !slices.Equal(got, []string{"exec-1"}) {
+		t.Errorf("%s = %v, want [exec-1]", want[1], got)
+	}
+}
+
+func executionIDs(t *testing.T, path string) []string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var ids []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var r Record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		ids = append(ids, r.ExecutionID)
+	}
+	return ids
+}
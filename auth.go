@@ -0,0 +1,109 @@
+package grepo
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// Requirements are the roles and permissions a principal must all hold to
+// execute a use case. Those of the use case and of each of its groups add up.
+type Requirements struct {
+	Roles       []string `json:",omitempty"`
+	Permissions []string `json:",omitempty"`
+}
+
+func (r Requirements) IsZero() bool {
+	return len(r.Roles) == 0 && len(r.Permissions) == 0
+}
+
+func (r Requirements) merge(o Requirements) Requirements {
+	for _, role := range o.Roles {
+		if !slices.Contains(r.Roles, role) {
+			r.Roles = append(r.Roles, role)
+		}
+	}
+	for _, perm := range o.Permissions {
+		if !slices.Contains(r.Permissions, perm) {
+			r.Permissions = append(r.Permissions, perm)
+		}
+	}
+	return r
+}
+
+func (p *Principal) HasRole(role string) bool {
+	return p != nil && slices.Contains(p.Roles, role)
+}
+
+func (p *Principal) HasPermission(perm string) bool {
+	return p != nil && slices.Contains(p.Permissions, perm)
+}
+
+// Authorizer decides whether p may execute desc. It runs before the before
+// hooks, with the requirements the API resolved for desc.
+type Authorizer interface {
+	Authorize(ctx context.Context, p *Principal, desc Descriptor, req Requirements) error
+}
+
+type AuthorizerFunc func(ctx context.Context, p *Principal, desc Descriptor, req Requirements) error
+
+func (fn AuthorizerFunc) Authorize(ctx context.Context, p *Principal, desc Descriptor, req Requirements) error {
+	return fn(ctx, p, desc, req)
+}
+
+// RequirementsAuthorizer is the default Authorizer. It requires a principal
+// holding every required role and permission, and lets anyone execute use
+// cases without requirements.
+func RequirementsAuthorizer() Authorizer {
+	return AuthorizerFunc(func(ctx context.Context, p *Principal, desc Descriptor, req Requirements) error {
+		if req.IsZero() {
+			return nil
+		}
+		if p == nil {
+			return NewError(CodeUnauthenticated, "authentication required")
+		}
+		var missing Requirements
+		for _, role := range req.Roles {
+			if !p.HasRole(role) {
+				missing.Roles = append(missing.Roles, role)
+			}
+		}
+		for _, perm := range req.Permissions {
+			if !p.HasPermission(perm) {
+				missing.Permissions = append(missing.Permissions, perm)
+			}
+		}
+		if missing.IsZero() {
+			return nil
+		}
+		// The message reaches callers, so the principal is only named in the
+		// cause, which stays server side.
+		cause := fmt.Errorf("principal %s lacks the requirements", p.ID)
+		return WrapError(CodePermissionDenied, cause, fmt.Sprintf("not allowed to execute %s", desc.Operation())).
+			WithDetail("missing", missing)
+	})
+}
+
+// Requirements returns the requirements of d merged with those of its groups.
+func (a *API) Requirements(d Descriptor) Requirements {
+	var req Requirements
+	for _, g := range d.Groups() {
+		req = req.merge(g.requirements)
+	}
+	if r, ok := d.(interface{ Requirements() Requirements }); ok {
+		req = req.merge(r.Requirements())
+	}
+	return req
+}
+
+func (a *API) authorize(ctx context.Context, d Descriptor) error {
+	authorizer := a.options.authorizer
+	if authorizer == nil {
+		authorizer = RequirementsAuthorizer()
+	}
+	err := authorizer.Authorize(ctx, PrincipalFrom(ctx), d, a.Requirements(d))
+	if err != nil && CodeOf(err) == CodeUnknown {
+		return WrapError(CodePermissionDenied, err, "permission denied")
+	}
+	return err
+}
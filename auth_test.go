@@ -0,0 +1,131 @@
+package grepo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestAPI_Authorization(t *testing.T) {
+	newAPI := func(before *int, hooked *error, opts ...APIOptionFunc) *API {
+		group := NewGroup("users").WithRoles("staff").WithPermissions("users:read")
+		return NewAPIBuilder().
+			WithOptions(opts...).
+			AddBeforeHook(func(ctx context.Context, desc Descriptor, i any) (context.Context, error) {
+				*before++
+				return ctx, nil
+			}).
+			AddErrorHook(func(ctx context.Context, desc Descriptor, i any, err error) {
+				*hooked = err
+			}).
+			AddUseCase(NewUseCaseBuilder(&addOneUseCase{}).WithOperation("public").Build()).
+			AddUseCase(NewUseCaseBuilder(&validatedUseCase{}).
+				WithOperation("protected").
+				WithGroup(group).
+				WithPermissions("users:write", "users:read").
+				Build()).
+			Build()
+	}
+
+	tests := []struct {
+		name      string
+		opts      []APIOptionFunc
+		principal *Principal
+		operation string
+		input     any
+		wantCode  Code
+	}{
+		{name: "正常系: 要件なしは匿名で実行可能", operation: "public", input: TestInput{}},
+		{name: "異常系: 未認証", operation: "protected", input: validatedInput{}, wantCode: CodeUnauthenticated},
+		{
+			name:      "異常系: 権限不足",
+			principal: &Principal{ID: "u1", Roles: []string{"staff"}, Permissions: []string{"users:read"}},
+			operation: "protected",
+			input:     validatedInput{},
+			wantCode:  CodePermissionDenied,
+		},
+		{
+			name:      "正常系: 全ての要件を満たす",
+			principal: &Principal{ID: "u1", Roles: []string{"staff"}, Permissions: []string{"users:read", "users:write"}},
+			operation: "protected",
+			input:     validatedInput{},
+		},
+		{
+			name: "異常系: カスタムAuthorizerのエラーはPermissionDenied",
+			opts: []APIOptionFunc{WithAuthorizer(AuthorizerFunc(func(ctx context.Context, p *Principal, desc Descriptor, req Requirements) error {
+				return errors.New("outside business hours")
+			}))},
+			operation: "public",
+			input:     TestInput{},
+			wantCode:  CodePermissionDenied,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := 0
+			var hooked error
+			api := newAPI(&before, &hooked, tt.opts...)
+			ctx := WithPrincipal(context.Background(), tt.principal)
+
+			_, err := api.ExecuteAny(ctx, tt.operation, tt.input)
+			if CodeOf(err) != tt.wantCode {
+				t.Fatalf("ExecuteAny() error = %v, want code %q", err, tt.wantCode)
+			}
+			if err == nil {
+				return
+			}
+			if before != 0 {
+				t.Errorf("before hooks ran %d times, want 0", before)
+			}
+			if hooked != err {
+				t.Errorf("error hook got %v, want %v", hooked, err)
+			}
+		})
+	}
+
+	t.Run("正常系: 不足している要件を詳細に含む", func(t *testing.T) {
+		before := 0
+		var hooked error
+		ctx := WithPrincipal(context.Background(), &Principal{ID: "u1", Permissions: []string{"users:read"}})
+		_, err := newAPI(&before, &hooked).ExecuteAny(ctx, "protected", validatedInput{})
+		var gerr *Error
+		if !errors.As(err, &gerr) {
+			t.Fatalf("ExecuteAny() error = %v", err)
+		}
+		if got := fmt.Sprintf("%+v", gerr.Details["missing"]); got != "{Roles:[staff] Permissions:[users:write]}" {
+			t.Errorf("Details[missing] = %v", got)
+		}
+		if gerr.Message != "not allowed to execute protected" {
+			t.Errorf("Message = %q, want no principal ID", gerr.Message)
+		}
+		if gerr.Cause == nil || !strings.Contains(gerr.Cause.Error(), "u1") {
+			t.Errorf("Cause = %v, want the principal ID", gerr.Cause)
+		}
+	})
+
+	t.Run("正常系: 仕様に要件と認可エラーが含まれる", func(t *testing.T) {
+		before := 0
+		var hooked error
+		b, err := json.Marshal(newAPI(&before, &hooked))
+		if err != nil {
+			t.Fatalf("MarshalJSON() error = %v", err)
+		}
+		var spec map[string]struct {
+			Errors   []Code
+			Requires *Requirements
+		}
+		if err := json.Unmarshal(b, &spec); err != nil {
+			t.Fatalf("json.Unmarshal() error = %v", err)
+		}
+		if got := spec["protected"]; fmt.Sprintf("%+v", *got.Requires) != "{Roles:[staff] Permissions:[users:read users:write]}" ||
+			fmt.Sprint(got.Errors) != "[Unauthenticated PermissionDenied]" {
+			t.Errorf("spec = %+v", got)
+		}
+		if got := spec["public"]; got.Requires != nil || got.Errors != nil {
+			t.Errorf("spec = %+v", got)
+		}
+	})
+}
@@ -3,17 +3,27 @@ package cli
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"reflect"
 	"strings"
+	"text/tabwriter"
 
 	"github.com/ralsnet/grepo"
+	"github.com/ralsnet/grepo/openapi"
 	"github.com/ralsnet/grepo/refl"
+	"github.com/ralsnet/grepo/schema"
+	"github.com/ralsnet/grepo/tracing"
 	"github.com/spf13/cobra"
 )
 
+// TraceparentEnv names the environment variable carrying the W3C traceparent
+// of the process that runs the command.
+const TraceparentEnv = "TRACEPARENT"
+
 type apikey struct{}
 
 func WithAPIContext(ctx context.Context, api *grepo.API) context.Context {
@@ -29,21 +39,77 @@ func New(api *grepo.API, name string, setups ...SetupFunc) *cobra.Command {
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
 			ctx = WithAPIContext(ctx, api)
+			ctx = grepo.WithTransport(ctx, grepo.TransportCLI)
+			if sc, err := tracing.ParseTraceparent(os.Getenv(TraceparentEnv)); err == nil {
+				ctx = tracing.ContextWithRemoteSpanContext(ctx, sc)
+			}
 			cmd.SetContext(ctx)
 			return nil
 		},
 	}
 
 	for _, uc := range api.UseCases() {
-		cmd := newUseCaseCommand(uc, setups...)
+		cmd := newUseCaseCommand(api, uc, setups...)
 		rootCmd.AddCommand(cmd)
+		cmd.Example = examples(cmd.CommandPath(), grepo.MetadataOf(uc).Examples)
 	}
 	rootCmd.AddCommand(specCmd(api))
 
 	return rootCmd
 }
 
-func newUseCaseCommand(uc grepo.Descriptor, setups ...SetupFunc) *cobra.Command {
+// fieldsHelp lists the fields reachable from t, one per line, with their
+// documentation, default and example.
+func fieldsHelp(t *refl.Type) string {
+	b := strings.Builder{}
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	refl.Walk(t, func(path string, f *refl.Field) {
+		presence := "required"
+		if f.Optional {
+			presence = "optional"
+		}
+		doc := f.Description
+		if f.Default != "" {
+			doc = strings.TrimSpace(fmt.Sprintf("%s (default: %s)", doc, f.Default))
+		}
+		if f.Example != "" {
+			doc = strings.TrimSpace(fmt.Sprintf("%s (example: %s)", doc, f.Example))
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", path, f.Type.Name, presence, doc)
+	})
+	w.Flush()
+
+	out := strings.Builder{}
+	for line := range strings.Lines(b.String()) {
+		out.WriteString(strings.TrimRight(line, " \n"))
+		out.WriteString("\n")
+	}
+	return out.String()
+}
+
+// examples renders use case examples as invocations of the command at path.
+func examples(path string, examples []grepo.Example) string {
+	b := strings.Builder{}
+	for i, ex := range examples {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		input, err := json.Marshal(ex.Input)
+		if err != nil {
+			continue
+		}
+		if ex.Name != "" {
+			b.WriteString(fmt.Sprintf("  # %s\n", ex.Name))
+		}
+		b.WriteString(fmt.Sprintf("  %s '%s'\n", path, strings.ReplaceAll(string(input), "'", `'\''`)))
+		if output, err := json.Marshal(ex.Output); err == nil {
+			b.WriteString(fmt.Sprintf("  # => %s\n", output))
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func newUseCaseCommand(api *grepo.API, uc grepo.Descriptor, setups ...SetupFunc) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   uc.Operation(),
 		Short: uc.Description(),
@@ -58,6 +124,13 @@ func newUseCaseCommand(uc grepo.Descriptor, setups ...SetupFunc) *cobra.Command
 
 			output, err := api.ExecuteAny(ctx, uc.Operation(), input)
 			if err != nil {
+				// The arguments were accepted, so usage would not help here.
+				cmd.SilenceUsage = true
+				var verr *grepo.ValidationError
+				if errors.As(err, &verr) && grepo.CodeOf(err) == grepo.CodeInvalid {
+					cmd.SilenceErrors = true
+					printViolations(cmd.ErrOrStderr(), verr)
+				}
 				return err
 			}
 
@@ -78,9 +151,41 @@ func newUseCaseCommand(uc grepo.Descriptor, setups ...SetupFunc) *cobra.Command
 	if desc := uc.Description(); desc != "" {
 		b.WriteString(fmt.Sprintf("%s\n\n", desc))
 	}
+	meta := grepo.MetadataOf(uc)
+	cmd.Deprecated = meta.Deprecated
+	if meta.Experimental {
+		cmd.Short = strings.TrimSpace("[experimental] " + cmd.Short)
+		b.WriteString("Experimental: may change or be removed without deprecation.\n\n")
+	}
+	if len(meta.Tags) > 0 {
+		b.WriteString(fmt.Sprintf("Tags: %s\n", strings.Join(meta.Tags, ", ")))
+	}
+	switch {
+	case meta.ReadOnly:
+		b.WriteString("Read-only\n")
+	case meta.Idempotent:
+		b.WriteString("Idempotent\n")
+	}
+	if len(meta.Tags) > 0 || meta.ReadOnly || meta.Idempotent {
+		b.WriteString("\n")
+	}
+	if req := api.Requirements(uc); !req.IsZero() {
+		if len(req.Roles) > 0 {
+			b.WriteString(fmt.Sprintf("Required roles: %s\n", strings.Join(req.Roles, ", ")))
+		}
+		if len(req.Permissions) > 0 {
+			b.WriteString(fmt.Sprintf("Required permissions: %s\n", strings.Join(req.Permissions, ", ")))
+		}
+		b.WriteString("\n")
+	}
 
 	input := uc.Input()
 	inputSpec := refl.TypeOf(input)
+	if fields := fieldsHelp(inputSpec); fields != "" {
+		b.WriteString("Input fields:\n")
+		b.WriteString(fields)
+		b.WriteString("\n")
+	}
 	inputJSON, _ := json.MarshalIndent(inputSpec, "", "  ")
 	b.WriteString("Input schema:\n")
 	b.WriteString(string(inputJSON))
@@ -105,12 +210,35 @@ func newUseCaseCommand(uc grepo.Descriptor, setups ...SetupFunc) *cobra.Command
 	return cmd
 }
 
+const (
+	SpecFormatGrepo      = "grepo"
+	SpecFormatJSONSchema = "jsonschema"
+	SpecFormatOpenAPI    = "openapi"
+)
+
 func specCmd(api *grepo.API) *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "spec",
 		Short: "Show the API specification",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			b, err := json.MarshalIndent(api, "", "  ")
+			format, err := cmd.Flags().GetString("format")
+			if err != nil {
+				return err
+			}
+
+			var spec any
+			switch format {
+			case SpecFormatGrepo:
+				spec = api
+			case SpecFormatJSONSchema:
+				spec = jsonSchemaSpec(api)
+			case SpecFormatOpenAPI:
+				spec = openapi.Generate(api)
+			default:
+				return fmt.Errorf("unknown spec format %q", format)
+			}
+
+			b, err := json.MarshalIndent(spec, "", "  ")
 			if err != nil {
 				return err
 			}
@@ -121,6 +249,30 @@ func specCmd(api *grepo.API) *cobra.Command {
 			return nil
 		},
 	}
+	cmd.Flags().String("format", SpecFormatGrepo, fmt.Sprintf("Output format (%s, %s, %s)", SpecFormatGrepo, SpecFormatJSONSchema, SpecFormatOpenAPI))
+	return cmd
+}
+
+func jsonSchemaSpec(api *grepo.API) map[string]map[string]*schema.Schema {
+	spec := make(map[string]map[string]*schema.Schema)
+	for _, uc := range api.UseCases() {
+		spec[uc.Operation()] = map[string]*schema.Schema{
+			"Input":  schema.For(refl.TypeOf(uc.Input())),
+			"Output": schema.For(refl.TypeOf(uc.Output())),
+		}
+	}
+	return spec
+}
+
+func printViolations(w io.Writer, verr *grepo.ValidationError) {
+	fmt.Fprintf(w, "%s: %d violation(s)\n", grepo.ErrInvalid, len(verr.Violations))
+	for _, v := range verr.Violations {
+		path := v.Path
+		if path == "" {
+			path = "(root)"
+		}
+		fmt.Fprintf(w, "  - %s [%s]: %s\n", path, v.Constraint, v.Message)
+	}
 }
 
 func getInput(cmd *cobra.Command, args []string, uc grepo.Descriptor) (any, error) {
@@ -128,18 +280,27 @@ func getInput(cmd *cobra.Command, args []string, uc grepo.Descriptor) (any, erro
 
 	if flagInput, err := cmd.Flags().GetString("input"); err == nil && flagInput != "" {
 		b, err = os.ReadFile(flagInput)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, grepo.WrapError(grepo.CodeNotFound, err, "input file not found")
+		}
+		if err != nil {
+			return nil, grepo.WrapError(grepo.CodeInternal, err, "cannot read input file")
+		}
 	} else if len(args) > 0 {
 		b = []byte(args[0])
 	} else if flagStdin, err := cmd.Flags().GetBool("stdin"); err == nil && flagStdin {
 		stdin := cmd.InOrStdin()
 		b, err = io.ReadAll(stdin)
+		if err != nil {
+			return nil, grepo.WrapError(grepo.CodeInternal, err, "cannot read standard input")
+		}
 	} else {
 		b = []byte("{}")
 	}
 
 	p := reflect.New(reflect.TypeOf(uc.Input())).Interface()
 	if err := json.Unmarshal(b, p); err != nil {
-		return nil, err
+		return nil, grepo.WrapError(grepo.CodeInvalid, err, "malformed input")
 	}
 
 	return reflect.ValueOf(p).Elem().Interface(), nil
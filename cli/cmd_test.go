@@ -0,0 +1,201 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ralsnet/grepo"
+	"github.com/ralsnet/grepo/refl"
+)
+
+type greetInput struct {
+	Name    string       `json:"name" grepo:"minLen:2;desc:Name to greet;example:alice"`
+	Lang    string       `json:"lang" grepo:"optional;enum:en,ja;default:en" doc:"Language; en or ja"`
+	Options greetOptions `json:"options" grepo:"optional"`
+}
+
+type greetOptions struct {
+	Times int `json:"times" grepo:"optional;max:3;default:1"`
+}
+
+type greetOutput struct {
+	Message string `json:"message" grepo:"maxLen:40"`
+}
+
+type greetUseCase struct{}
+
+func (u *greetUseCase) Execute(ctx context.Context, in greetInput) (*greetOutput, error) {
+	return &greetOutput{Message: strings.Repeat("hello "+in.Name+" ", in.Options.Times)}, nil
+}
+
+func greetAPI() *grepo.API {
+	return grepo.NewAPIBuilder().
+		WithOptions(grepo.WithEnableInputValidation(), grepo.WithEnableOutputValidation()).
+		AddUseCase(grepo.NewUseCaseBuilder(&greetUseCase{}).
+			WithOperation("greet").
+			AddExample("Greet alice", greetInput{Name: "alice"}, greetOutput{Message: "hello alice "}).
+			AddExample("", greetInput{Name: "o'neil"}, greetOutput{Message: "hello o'neil "}).
+			Build()).
+		Build()
+}
+
+func TestFieldsHelp(t *testing.T) {
+	got := fieldsHelp(refl.TypeOf(greetInput{}))
+	want := `  name           string            required  Name to greet (example: alice)
+  lang           string            optional  Language; en or ja (default: en)
+  options        cli.greetOptions  optional
+  options.times  int               optional  (default: 1)
+`
+	if got != want {
+		t.Errorf("fieldsHelp() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestNew_Help(t *testing.T) {
+	root := New(greetAPI(), "test")
+	cmd, _, err := root.Find([]string{"greet"})
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"Input fields:\n  name ",
+		"Name to greet (example: alice)",
+		"Language; en or ja (default: en)",
+		"(default: 1)",
+		"Input schema:",
+	} {
+		if !strings.Contains(cmd.Long, want) {
+			t.Errorf("Long does not contain %q:\n%s", want, cmd.Long)
+		}
+	}
+
+	wantExample := `  # Greet alice
+  test greet '{"name":"alice","lang":"","options":{"times":0}}'
+  # => {"message":"hello alice "}
+
+  test greet '{"name":"o'\''neil","lang":"","options":{"times":0}}'
+  # => {"message":"hello o'neil "}`
+	if cmd.Example != wantExample {
+		t.Errorf("Example =\n%s\nwant\n%s", cmd.Example, wantExample)
+	}
+}
+
+func TestNew_Execute(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantStdout string
+		wantStderr string
+		wantErr    error
+		wantExit   int
+	}{
+		{
+			name:       "正常系: デフォルト値を適用して実行",
+			args:       []string{"greet", `{"name":"bob"}`},
+			wantStdout: `"message": "hello bob "`,
+		},
+		{
+			name: "異常系: 違反を一覧表示",
+			args: []string{"greet", `{"name":"b","lang":"fr","options":{"times":5}}`},
+			wantStderr: `Invalid: 3 violation(s)
+  - name [minLen]: has length 1 which is less than minLen 2
+  - lang [enum]: has value fr which is not in enum [en ja]
+  - options.times [max]: has value 5 which is greater than max 3
+`,
+			wantErr:  grepo.ErrInvalid,
+			wantExit: ExitDataErr,
+		},
+		{
+			name:       "異常系: 出力の違反は内部エラー",
+			args:       []string{"greet", `{"name":"bartholomew","options":{"times":3}}`},
+			wantStderr: "Error: Internal: invalid output: Invalid: field message has length 54 which is greater than maxLen 40\n",
+			wantErr:    grepo.ErrInternal,
+			wantExit:   ExitSoftware,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr, err := execute(context.Background(), New(greetAPI(), "test"), tt.args...)
+			if !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+				t.Fatalf("Execute() error = %v, want %v", err, tt.wantErr)
+			}
+			if !strings.Contains(stdout, tt.wantStdout) {
+				t.Errorf("stdout = %s, want %s", stdout, tt.wantStdout)
+			}
+			if got := ExitCode(err); got != tt.wantExit {
+				t.Errorf("ExitCode() = %v, want %v", got, tt.wantExit)
+			}
+			if stderr != tt.wantStderr {
+				t.Errorf("stderr =\n%s\nwant\n%s", stderr, tt.wantStderr)
+			}
+		})
+	}
+}
+
+type failingReader struct{}
+
+func (failingReader) Read(p []byte) (int, error) {
+	return 0, errors.New("broken pipe")
+}
+
+func TestNew_InputSource(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "input.json")
+	if err := os.WriteFile(file, []byte(`{"name":"bob"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantStdout string
+		wantErr    error
+		wantExit   int
+	}{
+		{
+			name:       "正常系: --inputのファイルから読み込む",
+			args:       []string{"greet", "--input", file},
+			wantStdout: `"message": "hello bob "`,
+		},
+		{
+			name:     "異常系: --inputのファイルが存在しない",
+			args:     []string{"greet", "--input", filepath.Join(dir, "missing.json")},
+			wantErr:  fs.ErrNotExist,
+			wantExit: ExitNoInput,
+		},
+		{
+			name:     "異常系: --inputがディレクトリで読み込めない",
+			args:     []string{"greet", "--input", dir},
+			wantErr:  grepo.ErrInternal,
+			wantExit: ExitSoftware,
+		},
+		{
+			name:     "異常系: 標準入力を読み込めない",
+			args:     []string{"greet", "--stdin"},
+			wantErr:  grepo.ErrInternal,
+			wantExit: ExitSoftware,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := New(greetAPI(), "test")
+			root.SetIn(failingReader{})
+			stdout, _, err := execute(context.Background(), root, tt.args...)
+			if !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+				t.Fatalf("Execute() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := ExitCode(err); got != tt.wantExit {
+				t.Errorf("ExitCode() = %v, want %v", got, tt.wantExit)
+			}
+			if !strings.Contains(stdout, tt.wantStdout) {
+				t.Errorf("stdout = %s, want %s", stdout, tt.wantStdout)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"github.com/ralsnet/grepo"
+)
+
+// Exit statuses follow the BSD sysexits(3) conventions where one applies.
+const (
+	ExitOK          = 0
+	ExitFailure     = 1
+	ExitDataErr     = 65
+	ExitNoInput     = 66
+	ExitUnavailable = 69
+	ExitSoftware    = 70
+	ExitTempFail    = 75
+	ExitNoPerm      = 77
+	ExitCanceled    = 130
+)
+
+// ExitCodes maps error codes to process exit statuses. Codes that are not
+// listed exit with ExitFailure.
+var ExitCodes = map[grepo.Code]int{
+	grepo.CodeInvalid:          ExitDataErr,
+	grepo.CodeConflict:         ExitDataErr,
+	grepo.CodeNotFound:         ExitNoInput,
+	grepo.CodeUnavailable:      ExitUnavailable,
+	grepo.CodeInternal:         ExitSoftware,
+	grepo.CodeDeadlineExceeded: ExitTempFail,
+	grepo.CodeUnauthenticated:  ExitNoPerm,
+	grepo.CodePermissionDenied: ExitNoPerm,
+	grepo.CodeCanceled:         ExitCanceled,
+}
+
+// ExitCode returns the process exit status for an error returned by a command.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	if code, ok := ExitCodes[grepo.CodeOf(err)]; ok {
+		return code
+	}
+	return ExitFailure
+}
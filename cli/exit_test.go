@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ralsnet/grepo"
+)
+
+type failInput struct {
+	Code grepo.Code `json:"code"`
+}
+
+type failUseCase struct{}
+
+func (u *failUseCase) Execute(ctx context.Context, in failInput) (*whoamiOutput, error) {
+	return nil, grepo.NewError(in.Code, "failed")
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "正常系: エラーなし", err: nil, want: ExitOK},
+		{name: "異常系: Invalid", err: grepo.NewError(grepo.CodeInvalid, "bad"), want: ExitDataErr},
+		{name: "異常系: Conflict", err: grepo.ErrConflict, want: ExitDataErr},
+		{name: "異常系: NotFound", err: fmt.Errorf("user: %w", grepo.ErrNotFound), want: ExitNoInput},
+		{name: "異常系: Unavailable", err: grepo.ErrUnavailable, want: ExitUnavailable},
+		{name: "異常系: Internal", err: grepo.NewError(grepo.CodeInternal, "bug"), want: ExitSoftware},
+		{name: "異常系: DeadlineExceeded", err: context.DeadlineExceeded, want: ExitTempFail},
+		{name: "異常系: Unauthenticated", err: grepo.NewError(grepo.CodeUnauthenticated, "who"), want: ExitNoPerm},
+		{name: "異常系: PermissionDenied", err: grepo.NewError(grepo.CodePermissionDenied, "no"), want: ExitNoPerm},
+		{name: "異常系: Canceled", err: context.Canceled, want: ExitCanceled},
+		{name: "異常系: 分類されないエラー", err: errors.New("boom"), want: ExitFailure},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExitCode(tt.err); got != tt.want {
+				t.Errorf("ExitCode(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("正常系: ExitCodesで上書き", func(t *testing.T) {
+		code := grepo.Code("RateLimited")
+		ExitCodes[code] = ExitTempFail
+		t.Cleanup(func() { delete(ExitCodes, code) })
+		if got := ExitCode(grepo.NewError(code, "slow down")); got != ExitTempFail {
+			t.Errorf("ExitCode() = %d, want %d", got, ExitTempFail)
+		}
+	})
+}
+
+func TestExitCode_Command(t *testing.T) {
+	api := grepo.NewAPIBuilder().
+		AddUseCase(grepo.NewUseCaseBuilder(&failUseCase{}).WithOperation("fail").Build()).
+		Build()
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{name: "異常系: ユースケースのエラーコード", args: []string{"fail", `{"code":"NotFound"}`}, want: ExitNoInput},
+		{name: "異常系: 不正なJSON入力", args: []string{"fail", `{`}, want: ExitDataErr},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := execute(context.Background(), New(api, "test"), tt.args...)
+			if got := ExitCode(err); got != tt.want {
+				t.Errorf("ExitCode(%v) = %d, want %d", err, got, tt.want)
+			}
+		})
+	}
+}
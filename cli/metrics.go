@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"github.com/ralsnet/grepo"
+	"github.com/ralsnet/grepo/metrics"
+	"github.com/spf13/cobra"
+)
+
+// MetricsFileFlag adds a --metrics-file flag to every use case command. When
+// set, the registry is written to that path in the Prometheus text format
+// after the use case ran, whether it failed or not, for the node_exporter
+// textfile collector or a Pushgateway upload at the end of a batch job.
+func MetricsFileFlag(registry *metrics.Registry) SetupFunc {
+	return func(cmd *cobra.Command, uc grepo.Descriptor) {
+		cmd.Flags().String("metrics-file", "", "Path to write Prometheus metrics to after execution")
+
+		run := cmd.RunE
+		cmd.RunE = func(cmd *cobra.Command, args []string) error {
+			err := run(cmd, args)
+			path, _ := cmd.Flags().GetString("metrics-file")
+			if path == "" {
+				return err
+			}
+			if werr := registry.WriteFile(path); werr != nil && err == nil {
+				return werr
+			}
+			return err
+		}
+	}
+}
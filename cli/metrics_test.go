@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ralsnet/grepo"
+	"github.com/ralsnet/grepo/metrics"
+)
+
+func TestMetricsFileFlag(t *testing.T) {
+	registry := metrics.NewRegistry()
+	api := grepo.NewAPIBuilder().
+		AddErrorHook(metrics.HookError(registry)).
+		AddAfterHook(metrics.HookAfter(registry)).
+		AddUseCase(grepo.NewUseCaseBuilder(&whoamiUseCase{}).WithOperation("whoami").Build()).
+		AddUseCase(grepo.NewUseCaseBuilder(&failUseCase{}).WithOperation("fail").Build()).
+		Build()
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantErr  bool
+		wantLine string
+	}{
+		{
+			name:     "正常系: 実行後に書き出す",
+			args:     []string{"whoami", "{}"},
+			wantLine: `grepo_operation_calls_total{operation="whoami",group=""} 1`,
+		},
+		{
+			name:     "異常系: 失敗しても書き出す",
+			args:     []string{"fail", `{"code":"Unavailable"}`},
+			wantErr:  true,
+			wantLine: `grepo_operation_errors_total{operation="fail",group="",code="Unavailable"} 1`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry.Reset()
+			path := filepath.Join(t.TempDir(), "grepo.prom")
+			args := append(tt.args, "--metrics-file", path)
+			_, _, err := execute(context.Background(), New(api, "test", MetricsFileFlag(registry)), args...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			b, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(b), tt.wantLine+"\n") {
+				t.Errorf("metrics file =\n%s\nwant line %s", b, tt.wantLine)
+			}
+		})
+	}
+
+	t.Run("正常系: フラグなしでは書き出さない", func(t *testing.T) {
+		dir := t.TempDir()
+		t.Chdir(dir)
+		if _, _, err := execute(context.Background(), New(api, "test", MetricsFileFlag(registry)), "whoami", "{}"); err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+			t.Errorf("entries = %v", entries)
+		}
+	})
+
+	t.Run("異常系: 書き出せなければエラー", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "missing", "grepo.prom")
+		if _, _, err := execute(context.Background(), New(api, "test", MetricsFileFlag(registry)), "whoami", "{}", "--metrics-file", path); err == nil {
+			t.Error("Execute() error = nil, want error")
+		}
+	})
+}
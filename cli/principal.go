@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"os/user"
+
+	"github.com/ralsnet/grepo"
+	"github.com/spf13/cobra"
+)
+
+// WithLocalPrincipal makes the OS user running the command the principal of
+// every use case command, unless the context passed to Command.ExecuteContext
+// already carries one. Without it, commands run without a principal and use
+// cases guarded by an authorizer reject them.
+func WithLocalPrincipal() SetupFunc {
+	return func(cmd *cobra.Command, uc grepo.Descriptor) {
+		preRun := cmd.PreRunE
+		cmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+			if preRun != nil {
+				if err := preRun(cmd, args); err != nil {
+					return err
+				}
+			}
+			ctx := cmd.Context()
+			if grepo.PrincipalFrom(ctx) != nil {
+				return nil
+			}
+			if p := localPrincipal(); p != nil {
+				cmd.SetContext(grepo.WithPrincipal(ctx, p))
+			}
+			return nil
+		}
+	}
+}
+
+// localPrincipal identifies the OS user running the command.
+func localPrincipal() *grepo.Principal {
+	u, err := user.Current()
+	if err != nil {
+		return nil
+	}
+	return &grepo.Principal{ID: u.Username}
+}
@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os/user"
+	"testing"
+
+	"github.com/ralsnet/grepo"
+	"github.com/spf13/cobra"
+)
+
+type whoamiInput struct{}
+
+type whoamiOutput struct {
+	ID string `json:"id"`
+}
+
+type whoamiUseCase struct{}
+
+func (u *whoamiUseCase) Execute(ctx context.Context, in whoamiInput) (*whoamiOutput, error) {
+	if info := grepo.ExecutionInfoFrom(ctx); info != nil && info.Principal != nil {
+		return &whoamiOutput{ID: info.Principal.ID}, nil
+	}
+	return &whoamiOutput{}, nil
+}
+
+// execute runs root with args and returns what it wrote to stdout and stderr.
+func execute(ctx context.Context, root *cobra.Command, args ...string) (string, string, error) {
+	var stdout, stderr bytes.Buffer
+	root.SetOut(&stdout)
+	root.SetErr(&stderr)
+	root.SetArgs(args)
+	err := root.ExecuteContext(ctx)
+	return stdout.String(), stderr.String(), err
+}
+
+func TestWithLocalPrincipal(t *testing.T) {
+	api := grepo.NewAPIBuilder().
+		AddUseCase(grepo.NewUseCaseBuilder(&whoamiUseCase{}).WithOperation("whoami").Build()).
+		Build()
+	u, err := user.Current()
+	if err != nil {
+		t.Skipf("user.Current() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		setups []SetupFunc
+		want   string
+	}{
+		{
+			name: "正常系: 既定ではプリンシパルなし",
+			ctx:  context.Background(),
+			want: "",
+		},
+		{
+			name:   "正常系: 実行中のOSユーザー",
+			ctx:    context.Background(),
+			setups: []SetupFunc{WithLocalPrincipal()},
+			want:   u.Username,
+		},
+		{
+			name:   "正常系: ExecuteContextのプリンシパルを優先",
+			ctx:    grepo.WithPrincipal(context.Background(), &grepo.Principal{ID: "alice"}),
+			setups: []SetupFunc{WithLocalPrincipal()},
+			want:   "alice",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, _, err := execute(tt.ctx, New(api, "test", tt.setups...), "whoami", "{}")
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			var got whoamiOutput
+			if err := json.Unmarshal([]byte(stdout), &got); err != nil {
+				t.Fatalf("stdout = %s: %v", stdout, err)
+			}
+			if got.ID != tt.want {
+				t.Errorf("principal = %q, want %q", got.ID, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package grepo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewFakeClock(start)
+
+	t1 := c.NewTimer(time.Second)
+	t2 := c.NewTimer(2 * time.Second)
+	after := c.After(3 * time.Second)
+	fired := func(ch <-chan time.Time) bool {
+		select {
+		case <-ch:
+			return true
+		default:
+			return false
+		}
+	}
+
+	c.Advance(time.Second)
+	if !fired(t1.C()) || fired(t2.C()) || fired(after) {
+		t.Fatal("Advance(1s) fired the wrong timers")
+	}
+	if got := c.Since(start); got != time.Second {
+		t.Errorf("Since() = %v, want 1s", got)
+	}
+
+	if !t2.Stop() {
+		t.Error("Stop() = false for a pending timer")
+	}
+	if t1.Stop() {
+		t.Error("Stop() = true for a fired timer")
+	}
+	t1.Reset(time.Second)
+
+	c.Set(start.Add(5 * time.Second))
+	if !fired(t1.C()) || fired(t2.C()) || !fired(after) {
+		t.Fatal("Set() fired the wrong timers")
+	}
+	if !c.Now().Equal(start.Add(5 * time.Second)) {
+		t.Errorf("Now() = %v", c.Now())
+	}
+	if !fired(c.After(0)) {
+		t.Error("After(0) did not fire immediately")
+	}
+}
+
+type clockOutput struct {
+	Now time.Time
+}
+
+type clockUseCase struct{}
+
+func (u *clockUseCase) Execute(ctx context.Context, input TestInput) (*clockOutput, error) {
+	clock := ClockFrom(ctx)
+	if input.Value > 0 {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-clock.After(time.Duration(input.Value) * time.Second):
+		}
+	}
+	return &clockOutput{Now: clock.Now()}, nil
+}
+
+func TestAPI_WithClock(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("正常系: ExecuteTimeとClockFromがClockを使う", func(t *testing.T) {
+		c := NewFakeClock(start)
+		var executeTime time.Time
+		api := NewAPIBuilder().
+			WithOptions(WithClock(c)).
+			AddBeforeHook(func(ctx context.Context, desc Descriptor, i any) (context.Context, error) {
+				executeTime = ExecuteTime(ctx)
+				return ctx, nil
+			}).
+			AddUseCase(NewUseCaseBuilder(&clockUseCase{}).WithOperation("clock").Build()).
+			Build()
+
+		out, err := api.ExecuteAny(context.Background(), "clock", TestInput{})
+		if err != nil {
+			t.Fatalf("ExecuteAny() error = %v", err)
+		}
+		if !executeTime.Equal(start) || !out.(*clockOutput).Now.Equal(start) {
+			t.Errorf("ExecuteTime = %v, Now = %v, want %v", executeTime, out.(*clockOutput).Now, start)
+		}
+	})
+
+	t.Run("正常系: Clockを進めてタイムアウトさせる", func(t *testing.T) {
+		c := NewFakeClock(start)
+		api := NewAPIBuilder().
+			WithOptions(WithClock(c)).
+			AddUseCase(NewUseCaseBuilder(&clockUseCase{}).WithOperation("clock").WithTimeout(time.Minute).Build()).
+			Build()
+
+		done := make(chan error)
+		go func() {
+			_, err := api.ExecuteAny(context.Background(), "clock", TestInput{Value: 3600})
+			done <- err
+		}()
+		// The timeout and the use case's own timer.
+		c.BlockUntil(2)
+		c.Advance(time.Minute)
+
+		if err := <-done; CodeOf(err) != CodeDeadlineExceeded || !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("ExecuteAny() error = %v, want DeadlineExceeded", err)
+		}
+	})
+
+	t.Run("正常系: 実行外ではRealClock", func(t *testing.T) {
+		if _, ok := ClockFrom(context.Background()).(realClock); !ok {
+			t.Error("ClockFrom() is not the real clock")
+		}
+	})
+}
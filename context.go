@@ -2,6 +2,8 @@ package grepo
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"time"
 )
 
@@ -9,17 +11,111 @@ type ctxkey string
 
 const (
 	ctxkeyExecuteTime ctxkey = "ExecuteTime"
+	ctxkeyAttempt     ctxkey = "Attempt"
+	ctxkeyClock       ctxkey = "Clock"
+	ctxkeyExecution   ctxkey = "Execution"
+	ctxkeyTransport   ctxkey = "Transport"
+	ctxkeyPrincipal   ctxkey = "Principal"
 )
 
+// Transports that invoke use cases.
+const (
+	TransportCLI  = "cli"
+	TransportHTTP = "http"
+	TransportTest = "test"
+)
+
+// ExecuteTime returns the time the current execution started, or the current
+// time of ClockFrom(ctx) outside of an execution.
 func ExecuteTime(ctx context.Context) time.Time {
 	if v := ctx.Value(ctxkeyExecuteTime); v != nil {
 		if t, ok := v.(time.Time); ok {
 			return t
 		}
 	}
-	return time.Now()
+	return ClockFrom(ctx).Now()
 }
 
 func WithExecuteTime(ctx context.Context, t time.Time) context.Context {
 	return context.WithValue(ctx, ctxkeyExecuteTime, t)
 }
+
+// Attempt returns the 1-based attempt number of the current execution.
+func Attempt(ctx context.Context) int {
+	if v, ok := ctx.Value(ctxkeyAttempt).(int); ok {
+		return v
+	}
+	return 1
+}
+
+func WithAttempt(ctx context.Context, attempt int) context.Context {
+	return context.WithValue(ctx, ctxkeyAttempt, attempt)
+}
+
+// Principal is the authenticated caller of an execution.
+type Principal struct {
+	ID          string
+	Roles       []string
+	Permissions []string
+}
+
+// ExecutionInfo describes a single execution of a use case. Executions started
+// from within another execution record its ID as ParentID.
+type ExecutionInfo struct {
+	ID        string
+	ParentID  string
+	Operation string
+	Transport string
+	Principal *Principal
+	// StartTime is read from the API's Clock as the execution begins, before
+	// authorization and the before hooks.
+	StartTime time.Time
+}
+
+// ExecutionInfoFrom returns the ExecutionInfo of the current execution, or nil
+// outside of an execution.
+func ExecutionInfoFrom(ctx context.Context) *ExecutionInfo {
+	info, _ := ctx.Value(ctxkeyExecution).(*ExecutionInfo)
+	return info
+}
+
+// Elapsed returns the time since the current execution started, measured with
+// its Clock. It reports false outside of an execution.
+func Elapsed(ctx context.Context) (time.Duration, bool) {
+	info := ExecutionInfoFrom(ctx)
+	if info == nil || info.StartTime.IsZero() {
+		return 0, false
+	}
+	return ClockFrom(ctx).Since(info.StartTime), true
+}
+
+func withExecutionInfo(ctx context.Context, info *ExecutionInfo) context.Context {
+	return context.WithValue(ctx, ctxkeyExecution, info)
+}
+
+// WithTransport records the transport that invokes use cases with ctx.
+func WithTransport(ctx context.Context, transport string) context.Context {
+	return context.WithValue(ctx, ctxkeyTransport, transport)
+}
+
+func TransportFrom(ctx context.Context) string {
+	transport, _ := ctx.Value(ctxkeyTransport).(string)
+	return transport
+}
+
+// WithPrincipal records the caller of the use cases invoked with ctx.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, ctxkeyPrincipal, p)
+}
+
+func PrincipalFrom(ctx context.Context) *Principal {
+	p, _ := ctx.Value(ctxkeyPrincipal).(*Principal)
+	return p
+}
+
+// newExecutionID returns 16 random bytes in hex, the format of a W3C trace ID.
+func newExecutionID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,105 @@
+package grepo
+
+import (
+	"reflect"
+
+	"github.com/ralsnet/grepo/refl"
+)
+
+// defaultTypes returns the types within t that hold a field with a default,
+// directly or through their fields and elements, or nil when there are none.
+// It is computed once per plan so that applying defaults skips the rest.
+func defaultTypes(t *refl.Type) map[*refl.Type]bool {
+	types := make(map[*refl.Type]bool)
+	// A Ref is marked through the type it resolves to, which is only known
+	// once that type is done, so repeat until nothing new is marked.
+	for n := -1; n != len(types); {
+		n = len(types)
+		markDefaults(t, types)
+	}
+	if len(types) == 0 {
+		return nil
+	}
+	return types
+}
+
+func markDefaults(t *refl.Type, types map[*refl.Type]bool) bool {
+	if t.Ref {
+		return types[t.Resolve()]
+	}
+	found := false
+	for _, f := range t.Fields {
+		// Visit every field so that all nested types are marked.
+		if markDefaults(f.Type, types) || f.Default != "" {
+			found = true
+		}
+	}
+	if t.Kind == refl.KindArray && markDefaults(t.Element, types) {
+		found = true
+	}
+	if found {
+		types[t] = true
+	}
+	return found
+}
+
+// applyDefaults returns a copy of input in which zero-valued optional fields
+// with a default tag entry hold their default. Pointers and slices leading to
+// such fields are copied so that the caller's value is left untouched; map
+// values are not filled in. types comes from defaultTypes(t).
+func applyDefaults(input any, t *refl.Type, types map[*refl.Type]bool) any {
+	v := reflect.ValueOf(input)
+	if !v.IsValid() {
+		return input
+	}
+	p := reflect.New(v.Type())
+	p.Elem().Set(v)
+	setDefaults(p.Elem(), t, types)
+	return p.Elem().Interface()
+}
+
+func setDefaults(v reflect.Value, t *refl.Type, types map[*refl.Type]bool) {
+	t = t.Resolve()
+	if !types[t] {
+		return
+	}
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return
+		}
+		c := reflect.New(v.Type().Elem())
+		c.Elem().Set(v.Elem())
+		v.Set(c)
+		setDefaults(c.Elem(), t, types)
+		return
+	}
+
+	switch {
+	case t.Kind == refl.KindObject && v.Kind() == reflect.Struct:
+		for _, f := range t.Fields {
+			fv, err := v.FieldByIndexErr(f.Index)
+			if err != nil || !fv.CanSet() {
+				continue
+			}
+			if f.Optional && f.Err() == nil && fv.IsZero() {
+				if d, ok := f.DefaultValue(); ok {
+					fv.Set(d)
+					continue
+				}
+			}
+			setDefaults(fv, f.Type, types)
+		}
+	case t.Kind == refl.KindArray && (v.Kind() == reflect.Slice || v.Kind() == reflect.Array):
+		if v.Kind() == reflect.Slice {
+			if v.IsNil() {
+				return
+			}
+			c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+			reflect.Copy(c, v)
+			v.Set(c)
+		}
+		for i := 0; i < v.Len(); i++ {
+			setDefaults(v.Index(i), t.Element, types)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package grepo
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ralsnet/grepo/refl"
+)
+
+type defaultsItem struct {
+	Name     string `json:"name"`
+	Quantity int    `json:"quantity" grepo:"optional;default:1;min:1"`
+}
+
+type defaultsInput struct {
+	Query    string          `json:"query" grepo:"optional;default:*"`
+	Limit    *int            `json:"limit" grepo:"optional;default:20;max:100"`
+	Sort     []string        `json:"sort" grepo:"optional;default:[\"name\"]"`
+	Mode     string          `json:"mode"`
+	Items    []*defaultsItem `json:"items" grepo:"optional"`
+	Fallback *defaultsItem   `json:"fallback" grepo:"optional"`
+}
+
+type defaultsOutput struct {
+	Input defaultsInput
+}
+
+type defaultsUseCase struct{}
+
+func (u *defaultsUseCase) Execute(ctx context.Context, in defaultsInput) (*defaultsOutput, error) {
+	return &defaultsOutput{Input: in}, nil
+}
+
+type badDefaultInput struct {
+	Limit int    `grepo:"optional;default:ten"`
+	Mode  string `grepo:"default:fast"`
+}
+
+type badDefaultUseCase struct{}
+
+func (u *badDefaultUseCase) Execute(ctx context.Context, in badDefaultInput) (*defaultsOutput, error) {
+	return &defaultsOutput{}, nil
+}
+
+type defaultsTree struct {
+	Name     string          `json:"name"`
+	Kind     string          `json:"kind" grepo:"optional;default:leaf;enum:leaf,branch"`
+	Children []*defaultsTree `json:"children" grepo:"optional"`
+	Branch   *defaultsBranch `json:"branch" grepo:"optional"`
+}
+
+type defaultsBranch struct {
+	Tree *defaultsTree `json:"tree" grepo:"optional"`
+}
+
+type defaultsTreeUseCase struct{}
+
+func (u *defaultsTreeUseCase) Execute(ctx context.Context, in defaultsTree) (*defaultsTree, error) {
+	if in.Name == "broken" {
+		return &defaultsTree{Name: "out", Children: []*defaultsTree{{Name: "c", Children: []*defaultsTree{{}}}}}, nil
+	}
+	return &in, nil
+}
+
+func TestAPI_Defaults(t *testing.T) {
+	var seen *defaultsInput
+	api := NewAPIBuilder().
+		WithOptions(WithEnableInputValidation()).
+		AddBeforeHook(func(ctx context.Context, desc Descriptor, i any) (context.Context, error) {
+			if in, ok := i.(defaultsInput); ok {
+				seen = &in
+			}
+			return ctx, nil
+		}).
+		AddUseCase(NewUseCaseBuilder(&defaultsUseCase{}).WithOperation("search").Build()).
+		Build()
+
+	t.Run("正常系: ゼロ値の任意フィールドにデフォルト値を設定", func(t *testing.T) {
+		item := &defaultsItem{Name: "a"}
+		in := defaultsInput{Mode: "slow", Items: []*defaultsItem{item}}
+		out, err := UseCase[defaultsInput, defaultsOutput](api, "search").Execute(context.Background(), in)
+		if err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		got := out.Input
+		if got.Query != "*" || got.Limit == nil || *got.Limit != 20 || len(got.Sort) != 1 || got.Sort[0] != "name" ||
+			got.Mode != "slow" || got.Items[0].Quantity != 1 || got.Fallback != nil {
+			t.Errorf("input = %+v", got)
+		}
+		if seen == nil || seen.Query != "*" {
+			t.Errorf("before hook input = %+v", seen)
+		}
+		if item.Quantity != 0 || in.Items[0] != item {
+			t.Errorf("caller's input was modified: %+v", item)
+		}
+	})
+
+	t.Run("正常系: 指定した値は上書きしない", func(t *testing.T) {
+		limit := 5
+		out, err := UseCase[defaultsInput, defaultsOutput](api, "search").Execute(context.Background(), defaultsInput{Query: "q", Limit: &limit, Mode: "m"})
+		if err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		if out.Input.Query != "q" || *out.Input.Limit != 5 || out.Input.Items != nil {
+			t.Errorf("input = %+v", out.Input)
+		}
+	})
+
+	t.Run("異常系: デフォルト値は必須フィールドのバリデーションを免れない", func(t *testing.T) {
+		_, err := UseCase[defaultsInput, defaultsOutput](api, "search").Execute(context.Background(), defaultsInput{})
+		var verr *ValidationError
+		if !errors.As(err, &verr) || len(verr.Violations) != 1 || verr.Violations[0].Path != "mode" {
+			t.Errorf("Execute() error = %v", err)
+		}
+	})
+
+	t.Run("異常系: 型に合わないデフォルト値と必須フィールドのデフォルト値はビルドエラー", func(t *testing.T) {
+		_, err := NewAPIBuilder().
+			AddUseCase(NewUseCaseBuilder(&badDefaultUseCase{}).WithOperation("bad").Build()).
+			BuildE()
+		if err == nil {
+			t.Fatal("BuildE() error = nil")
+		}
+		for _, want := range []string{`bad: Input field Limit has malformed tag: grepo tag "default:ten"`, "bad: Input field Mode has a default but is not optional"} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("BuildE() error = %v, want %q", err, want)
+			}
+		}
+	})
+}
+
+func TestAPI_Defaults_Recursive(t *testing.T) {
+	api := NewAPIBuilder().
+		WithOptions(WithEnableInputValidation(), WithEnableOutputValidation()).
+		AddUseCase(NewUseCaseBuilder(&defaultsTreeUseCase{}).WithOperation("tree").Build()).
+		Build()
+	uc := UseCase[defaultsTree, defaultsTree](api, "tree")
+
+	t.Run("正常系: 再帰的な型の各階層にデフォルト値を設定", func(t *testing.T) {
+		in := defaultsTree{
+			Name:     "root",
+			Children: []*defaultsTree{{Name: "c", Children: []*defaultsTree{{Name: "gc"}}}},
+			Branch:   &defaultsBranch{Tree: &defaultsTree{Name: "b"}},
+		}
+		out, err := uc.Execute(context.Background(), in)
+		if err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		for _, got := range []*defaultsTree{out, out.Children[0], out.Children[0].Children[0], out.Branch.Tree} {
+			if got.Kind != "leaf" {
+				t.Errorf("%s: Kind = %q, want leaf", got.Name, got.Kind)
+			}
+		}
+	})
+
+	t.Run("異常系: 再帰的な入力の深い階層を検証する", func(t *testing.T) {
+		in := defaultsTree{Name: "root", Children: []*defaultsTree{{Name: "c", Children: []*defaultsTree{{Name: "gc", Kind: "trunk"}}}}}
+		_, err := uc.Execute(context.Background(), in)
+		var verr *ValidationError
+		if !errors.As(err, &verr) || len(verr.Violations) != 1 || verr.Violations[0].Path != "children[0].children[0].kind" {
+			t.Errorf("Execute() error = %v", err)
+		}
+	})
+
+	t.Run("異常系: 再帰的な出力の深い階層を検証する", func(t *testing.T) {
+		_, err := uc.Execute(context.Background(), defaultsTree{Name: "broken"})
+		var verr *ValidationError
+		if !errors.As(err, &verr) || CodeOf(err) != CodeInternal || len(verr.Violations) != 1 || verr.Violations[0].Path != "children[0].children[0].name" {
+			t.Errorf("Execute() error = %v", err)
+		}
+	})
+}
+
+func TestDefaultTypes(t *testing.T) {
+	in := refl.TypeOf(defaultsInput{})
+	types := defaultTypes(in)
+	fields := make(map[string]*refl.Type)
+	for _, f := range in.Fields {
+		fields[f.Name] = f.Type
+	}
+	for _, tt := range []struct {
+		t    *refl.Type
+		want bool
+	}{
+		{t: in, want: true},
+		{t: fields["items"], want: true},
+		{t: fields["items"].Element, want: true},
+		{t: fields["fallback"], want: true},
+		{t: fields["query"], want: false},
+		{t: fields["mode"], want: false},
+	} {
+		if types[tt.t] != tt.want {
+			t.Errorf("defaultTypes()[%s] = %v, want %v", tt.t.Name, types[tt.t], tt.want)
+		}
+	}
+
+	tree := refl.TypeOf(defaultsTree{})
+	types = defaultTypes(tree)
+	branch := tree.Fields[3].Type
+	if !types[tree] || !types[tree.Fields[2].Type] || !types[branch] {
+		t.Errorf("defaultTypes() of a recursive type = %v", types)
+	}
+	if ref := branch.Fields[0].Type; !ref.Ref || types[ref] {
+		t.Errorf("defaultTypes()[%s] = %v, want a Ref left unmarked", ref.Name, types[ref])
+	}
+
+	if types := defaultTypes(refl.TypeOf(TestInput{})); types != nil {
+		t.Errorf("defaultTypes() = %v, want nil", types)
+	}
+}
@@ -1,10 +1,172 @@
 package grepo
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"maps"
+	"time"
 )
 
 var (
-	ErrNotFound = fmt.Errorf("NotFound")
-	ErrInvalid  = fmt.Errorf("Invalid")
+	ErrNotFound         = fmt.Errorf("NotFound")
+	ErrInvalid          = fmt.Errorf("Invalid")
+	ErrConflict         = fmt.Errorf("Conflict")
+	ErrUnauthenticated  = fmt.Errorf("Unauthenticated")
+	ErrPermissionDenied = fmt.Errorf("PermissionDenied")
+	ErrUnavailable      = fmt.Errorf("Unavailable")
+	ErrDeadlineExceeded = fmt.Errorf("DeadlineExceeded")
+	ErrCanceled         = fmt.Errorf("Canceled")
+	ErrInternal         = fmt.Errorf("Internal")
 )
+
+type Code string
+
+const (
+	CodeUnknown          Code = "Unknown"
+	CodeNotFound         Code = "NotFound"
+	CodeInvalid          Code = "Invalid"
+	CodeConflict         Code = "Conflict"
+	CodeUnauthenticated  Code = "Unauthenticated"
+	CodePermissionDenied Code = "PermissionDenied"
+	CodeUnavailable      Code = "Unavailable"
+	CodeDeadlineExceeded Code = "DeadlineExceeded"
+	CodeCanceled         Code = "Canceled"
+	CodeInternal         Code = "Internal"
+)
+
+var sentinels = []struct {
+	code Code
+	err  error
+}{
+	{CodeNotFound, ErrNotFound},
+	{CodeInvalid, ErrInvalid},
+	{CodeConflict, ErrConflict},
+	{CodeUnauthenticated, ErrUnauthenticated},
+	{CodePermissionDenied, ErrPermissionDenied},
+	{CodeUnavailable, ErrUnavailable},
+	{CodeDeadlineExceeded, ErrDeadlineExceeded},
+	{CodeCanceled, ErrCanceled},
+	{CodeInternal, ErrInternal},
+}
+
+// Error is an error classified by Code. Message is safe to show to callers,
+// while Cause carries the underlying error for logs and errors.Is/As.
+type Error struct {
+	Code    Code
+	Message string
+	Details map[string]any
+	Cause   error
+}
+
+func NewError(code Code, msg string) *Error {
+	return &Error{Code: code, Message: msg}
+}
+
+func Errorf(code Code, format string, args ...any) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+func WrapError(code Code, cause error, msg string) *Error {
+	return &Error{Code: code, Message: msg, Cause: cause}
+}
+
+func (e *Error) WithDetail(key string, value any) *Error {
+	details := make(map[string]any, len(e.Details)+1)
+	maps.Copy(details, e.Details)
+	details[key] = value
+	ee := *e
+	ee.Details = details
+	return &ee
+}
+
+func (e *Error) Error() string {
+	msg := string(e.Code)
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	if e.Cause != nil {
+		msg += ": " + e.Cause.Error()
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+func (e *Error) Is(target error) bool {
+	for _, s := range sentinels {
+		if s.code == e.Code {
+			return target == s.err
+		}
+	}
+	return false
+}
+
+// CodeOf classifies err. Errors that are neither an *Error nor wrap one of the
+// sentinels or a context error are reported as CodeUnknown.
+func CodeOf(err error) Code {
+	if err == nil {
+		return ""
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	for _, s := range sentinels {
+		if errors.Is(err, s.err) {
+			return s.code
+		}
+	}
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return CodeDeadlineExceeded
+	case errors.Is(err, context.Canceled):
+		return CodeCanceled
+	}
+	return CodeUnknown
+}
+
+// deadlineError reports an expired context as a CodeDeadlineExceeded Error,
+// leaving errors that are already classified untouched.
+func deadlineError(err error, timeout time.Duration) error {
+	var e *Error
+	if !errors.Is(err, context.DeadlineExceeded) || errors.As(err, &e) {
+		return err
+	}
+	e = WrapError(CodeDeadlineExceeded, err, "deadline exceeded")
+	if timeout > 0 {
+		e = e.WithDetail("timeout", timeout.String())
+	}
+	return e
+}
+
+// ErrorCodesOf returns the error codes declared by d, if it declares any.
+func ErrorCodesOf(d Descriptor) []Code {
+	if e, ok := d.(interface{ ErrorCodes() []Code }); ok {
+		return e.ErrorCodes()
+	}
+	return nil
+}
+
+// PanicError is the cause of the Error reported for a recovered panic.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func newPanicError(v any, stack []byte) *Error {
+	return WrapError(CodeInternal, &PanicError{Value: v, Stack: stack}, "internal error")
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
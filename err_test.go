@@ -0,0 +1,87 @@
+package grepo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestError(t *testing.T) {
+	cause := errors.New("duplicate key")
+	err := WrapError(CodeConflict, cause, "user already exists").WithDetail("id", "123")
+
+	if got, want := err.Error(), "Conflict: user already exists: duplicate key"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	if !errors.Is(err, ErrConflict) {
+		t.Error("errors.Is(err, ErrConflict) = false")
+	}
+	if errors.Is(err, ErrNotFound) {
+		t.Error("errors.Is(err, ErrNotFound) = true")
+	}
+	if !errors.Is(err, cause) {
+		t.Error("errors.Is(err, cause) = false")
+	}
+	if got := err.Details["id"]; got != "123" {
+		t.Errorf("Details[id] = %v", got)
+	}
+
+	base := NewError(CodeNotFound, "user not found")
+	_ = base.WithDetail("id", "1")
+	if base.Details != nil {
+		t.Errorf("WithDetail() modified the receiver: %v", base.Details)
+	}
+}
+
+func TestCodeOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want Code
+	}{
+		{name: "nil", err: nil, want: ""},
+		{name: "Error", err: NewError(CodePermissionDenied, "forbidden"), want: CodePermissionDenied},
+		{name: "wrapped Error", err: fmt.Errorf("get user: %w", Errorf(CodeNotFound, "user %s not found", "1")), want: CodeNotFound},
+		{name: "sentinel", err: fmt.Errorf("%w: missing", ErrNotFound), want: CodeNotFound},
+		{name: "ValidationError", err: &ValidationError{Violations: []*Violation{{Path: "ID"}}}, want: CodeInvalid},
+		{name: "deadline", err: fmt.Errorf("query: %w", context.DeadlineExceeded), want: CodeDeadlineExceeded},
+		{name: "canceled", err: context.Canceled, want: CodeCanceled},
+		{name: "unclassified", err: errors.New("boom"), want: CodeUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CodeOf(tt.err); got != tt.want {
+				t.Errorf("CodeOf() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPI_ErrorCodes(t *testing.T) {
+	api := NewAPIBuilder().
+		AddUseCase(NewUseCaseBuilder(&addOneUseCase{}).WithOperation("add_one").Build()).
+		AddUseCase(NewUseCaseBuilder(&errorUseCase{}).
+			WithOperation("error").
+			WithErrorCodes(CodeNotFound, CodeConflict, CodeNotFound).
+			Build()).
+		Build()
+
+	b, err := json.Marshal(api)
+	if err != nil {
+		t.Fatalf("MarshalJSON() error = %v", err)
+	}
+	var spec map[string]struct {
+		Errors []Code
+	}
+	if err := json.Unmarshal(b, &spec); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if got := spec["error"].Errors; fmt.Sprint(got) != "[NotFound Conflict]" {
+		t.Errorf("Errors = %v", got)
+	}
+	if got := spec["add_one"].Errors; got != nil {
+		t.Errorf("Errors = %v, want none", got)
+	}
+}
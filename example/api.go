@@ -2,12 +2,19 @@ package example
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"reflect"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/ralsnet/grepo"
+	"github.com/ralsnet/grepo/audit"
+	"github.com/ralsnet/grepo/example/entity"
 	"github.com/ralsnet/grepo/example/usecase"
 	"github.com/ralsnet/grepo/hooks"
+	"github.com/ralsnet/grepo/metrics"
 	"github.com/ralsnet/grepo/refl"
 )
 
@@ -15,30 +22,66 @@ func NewAPI(
 	findUser grepo.Executor[usecase.FindUsersInput, usecase.FindUsersOutput],
 	getUser grepo.Executor[usecase.GetUserInput, usecase.GetUserOutput],
 	saveUser grepo.Executor[usecase.SaveUserInput, usecase.SaveUserOutput],
+	registry *metrics.Registry,
+	auditSink audit.Sink,
 ) *grepo.API {
 	return grepo.NewAPIBuilder().
 		WithDescription("API example").
-		AddBeforeHook(hooks.HookBeforeSlog()).
-		AddAfterHook(hooks.HookAfterSlog()).
-		AddErrorHook(hooks.HookErrorSlog()).
+		AddBeforeHook(hooks.HookBeforeSlog(hooks.WithSlogExecutionID())).
+		AddBeforeHook(hooks.HookDeprecationSlog()).
+		AddAfterHook(hooks.HookAfterSlog(hooks.WithSlogExecutionID(), hooks.WithSlogDuration())).
+		AddAfterHook(metrics.HookAfter(registry)).
+		AddAfterHook(audit.HookAfter(auditSink)).
+		AddErrorHook(hooks.HookErrorSlog(hooks.WithSlogExecutionID(), hooks.WithSlogDuration(), hooks.WithSlogErrorCode())).
+		AddErrorHook(metrics.HookError(registry)).
+		AddErrorHook(audit.HookError(auditSink)).
 		WithOptions(
+			grepo.WithPanicRecovery(),
+			grepo.WithIDGenerator(func() string {
+				return uuid.Must(uuid.NewV7()).String()
+			}),
 			grepo.WithEnableInputValidation(),
 			grepo.WithEnableOutputValidation(),
-			grepo.WithCustomFieldValidators((grepo.FieldValidatorFunc(func(v reflect.Value, f *refl.Field) error {
-				fmt.Println(f.Parent().Name, f.Field, f.Type.Name)
+			grepo.WithNamedFieldValidator("notReserved", grepo.FieldValidatorFunc(func(v reflect.Value, f *refl.Field) error {
+				if slices.Contains([]string{"admin", "root"}, strings.ToLower(v.String())) {
+					return errors.New("is reserved")
+				}
 				return nil
-			}))),
+			})),
 		).
 		AddUseCase(
 			grepo.NewUseCaseBuilder(findUser).
+				WithTags("users").
+				WithReadOnly().
+				WithTimeout(2 * time.Second).
 				Build(),
 		).
 		AddUseCase(
 			grepo.NewUseCaseBuilder(getUser).
+				WithTags("users").
+				WithErrorCodes(grepo.CodeNotFound).
+				WithReadOnly().
+				AddExample("existing user", usecase.GetUserInput{ID: "0190a1b2-c3d4-7e5f-8a9b-0c1d2e3f4a5b"}, usecase.GetUserOutput{
+					User: &entity.User{
+						ID:        "0190a1b2-c3d4-7e5f-8a9b-0c1d2e3f4a5b",
+						Name:      "alice",
+						Authority: entity.AuthorityUser,
+						CreatedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+						UpdatedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+					},
+				}).
+				WithRetryPolicy(grepo.RetryPolicy{
+					MaxAttempts: 3,
+					Backoff:     100 * time.Millisecond,
+					Jitter:      0.2,
+				}).
 				Build(),
 		).
 		AddUseCase(
 			grepo.NewUseCaseBuilder(saveUser).
+				WithTags("users").
+				WithRoles("admin").
+				WithAuditable().
 				AddBeforeHook(func(ctx context.Context, i *usecase.SaveUserInput) (context.Context, error) {
 					if i.Authority != "admin" && i.Authority != "user" {
 						i.Authority = "user"
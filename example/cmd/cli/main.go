@@ -5,6 +5,7 @@ import (
 	"log/slog"
 	"os"
 
+	"github.com/ralsnet/grepo"
 	"github.com/ralsnet/grepo/example/internal"
 	"github.com/spf13/cobra"
 )
@@ -15,7 +16,9 @@ var rootCmd = &cobra.Command{
 }
 
 func Execute() error {
-	return rootCmd.Execute()
+	ctx := grepo.WithTransport(context.Background(), grepo.TransportCLI)
+	ctx = grepo.WithPrincipal(ctx, internal.LocalPrincipal())
+	return rootCmd.ExecuteContext(ctx)
 }
 
 func init() {
@@ -1,16 +1,20 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 
+	"github.com/ralsnet/grepo"
 	"github.com/ralsnet/grepo/cli"
 	"github.com/ralsnet/grepo/example/internal"
 )
 
 func main() {
 	api := internal.InitializeAPI()
-	if err := cli.New(api, "clitest").Execute(); err != nil {
+	ctx := grepo.WithPrincipal(context.Background(), internal.LocalPrincipal())
+	if err := cli.New(api, "clitest", cli.MetricsFileFlag(internal.Metrics)).ExecuteContext(ctx); err != nil {
 		fmt.Fprintln(os.Stderr, err)
+		os.Exit(cli.ExitCode(err))
 	}
 }
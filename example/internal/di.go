@@ -1,12 +1,27 @@
 package internal
 
 import (
+	"context"
+	"log/slog"
+
 	"github.com/ralsnet/grepo"
+	"github.com/ralsnet/grepo/audit"
 	"github.com/ralsnet/grepo/example"
 	"github.com/ralsnet/grepo/example/internal/local"
 	"github.com/ralsnet/grepo/example/usecase"
+	"github.com/ralsnet/grepo/metrics"
 )
 
+// Metrics collects the executions of every API built by InitializeAPI.
+var Metrics = metrics.NewRegistry()
+
+// auditSink logs audit records with the default logger. Use
+// audit.NewFileSink to keep them in an append-only file instead.
+var auditSink = audit.SinkFunc(func(ctx context.Context, r *audit.Record) error {
+	slog.InfoContext(ctx, "Audit", "record", r)
+	return nil
+})
+
 func InitializeAPI() *grepo.API {
 	repoUser := local.NewRepoUser(".")
 
@@ -14,5 +29,11 @@ func InitializeAPI() *grepo.API {
 	ucGetUser := usecase.NewGetUser(repoUser)
 	ucSaveUser := usecase.NewSaveUser(repoUser)
 
-	return example.NewAPI(ucFindUser, ucGetUser, ucSaveUser)
+	return example.NewAPI(ucFindUser, ucGetUser, ucSaveUser, Metrics, auditSink)
+}
+
+// LocalPrincipal is the operator of the example's command line tools, who
+// administers the local user store.
+func LocalPrincipal() *grepo.Principal {
+	return &grepo.Principal{ID: "local", Roles: []string{"admin"}}
 }
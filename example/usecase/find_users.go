@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"context"
+	"errors"
 
 	"github.com/ralsnet/grepo"
 	"github.com/ralsnet/grepo/example/entity"
@@ -11,14 +12,21 @@ import (
 const FindUsersOperation = "FindUsers"
 
 type FindUsersInput struct {
-	IDs  []string `grepo:"optional:true"`
-	Name string   `grepo:"optional:true"`
+	IDs  []string `grepo:"optional:true;desc:IDs of the users to find"`
+	Name string   `grepo:"optional:true;desc:Substring of the user name;example:ali"`
 }
 
 type FindUsersOutput struct {
 	Users []*entity.User `grepo:"optional:true"`
 }
 
+func (i FindUsersInput) Validate(ctx context.Context) error {
+	if len(i.IDs) == 0 && i.Name == "" {
+		return errors.New("either IDs or Name must be set")
+	}
+	return nil
+}
+
 type FindUsers struct {
 	repoUser port.RepoUser
 }
@@ -2,7 +2,6 @@ package usecase
 
 import (
 	"context"
-	"errors"
 
 	"github.com/ralsnet/grepo"
 	"github.com/ralsnet/grepo/example/entity"
@@ -36,7 +35,7 @@ func (uc *GetUser) Execute(ctx context.Context, input GetUserInput) (*GetUserOut
 	}
 
 	if user == nil {
-		return nil, errors.New("user not found")
+		return nil, grepo.NewError(grepo.CodeNotFound, "user not found").WithDetail("id", input.ID)
 	}
 
 	return &GetUserOutput{
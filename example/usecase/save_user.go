@@ -12,8 +12,8 @@ import (
 const SaveUserOperation = "SaveUser"
 
 type SaveUserInput struct {
-	Name      string
-	Authority string `grepo:"enum:admin,user"`
+	Name      string `grepo:"custom:notReserved;desc:Display name;example:alice"`
+	Authority string `grepo:"optional:true;enum:admin,user;default:user"`
 }
 
 type SaveUserOutput struct {
@@ -1,15 +1,28 @@
 package grepo
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type BeforeHook[I any] func(ctx context.Context, desc Descriptor, i I) (context.Context, error)
 type AfterHook[I any, O any] func(ctx context.Context, desc Descriptor, i I, o O)
 type ErrorHook[I any] func(ctx context.Context, desc Descriptor, i I, e error)
 
+// Next continues the execution wrapped by a Middleware.
+type Next[I any, O any] func(ctx context.Context, i I) (O, error)
+
+// Middleware wraps the execution of a use case. It may run code around next,
+// call it several times, or return without calling it at all. Middleware runs
+// after the before hooks and input validation; whatever it returns goes
+// through output validation and the after hooks.
+type Middleware[I any, O any] func(ctx context.Context, desc Descriptor, i I, next Next[I, O]) (O, error)
+
 type GroupHook struct {
 	before []BeforeHook[any]
 	after  []AfterHook[any, any]
 	error  []ErrorHook[any]
+	around []Middleware[any, any]
 }
 
 func NewGroupHook() *GroupHook {
@@ -31,9 +44,18 @@ func (h *GroupHook) AddError(hook ErrorHook[any]) *GroupHook {
 	return h
 }
 
+func (h *GroupHook) AddMiddleware(m Middleware[any, any]) *GroupHook {
+	h.around = append(h.around, m)
+	return h
+}
+
 type Group struct {
-	name string
-	hook *GroupHook
+	name         string
+	hook         *GroupHook
+	timeout      time.Duration
+	retry        *RetryPolicy
+	requirements Requirements
+	auditable    bool
 }
 
 func NewGroup(name string) *Group {
@@ -47,6 +69,55 @@ func (g *Group) Name() string {
 	return g.name
 }
 
+// WithTimeout sets the default timeout of the use cases in the group.
+func (g *Group) WithTimeout(d time.Duration) *Group {
+	g.timeout = d
+	return g
+}
+
+func (g *Group) Timeout() time.Duration {
+	return g.timeout
+}
+
+// WithRetryPolicy sets the default retry policy of the idempotent use cases in
+// the group.
+func (g *Group) WithRetryPolicy(p RetryPolicy) *Group {
+	g.retry = &p
+	return g
+}
+
+func (g *Group) RetryPolicy() *RetryPolicy {
+	return g.retry
+}
+
+// WithRoles requires the callers of the use cases in the group to hold every
+// given role.
+func (g *Group) WithRoles(roles ...string) *Group {
+	g.requirements = g.requirements.merge(Requirements{Roles: roles})
+	return g
+}
+
+// WithPermissions requires the callers of the use cases in the group to hold
+// every given permission.
+func (g *Group) WithPermissions(perms ...string) *Group {
+	g.requirements = g.requirements.merge(Requirements{Permissions: perms})
+	return g
+}
+
+func (g *Group) Requirements() Requirements {
+	return g.requirements
+}
+
+// WithAuditable flags every use case in the group for the audit hooks.
+func (g *Group) WithAuditable() *Group {
+	g.auditable = true
+	return g
+}
+
+func (g *Group) Auditable() bool {
+	return g.auditable
+}
+
 func (g *Group) AddBeforeHook(hook BeforeHook[any]) *Group {
 	g.hook.AddBefore(hook)
 	return g
@@ -62,6 +133,11 @@ func (g *Group) AddErrorHook(hook ErrorHook[any]) *Group {
 	return g
 }
 
+func (g *Group) AddMiddleware(m Middleware[any, any]) *Group {
+	g.hook.AddMiddleware(m)
+	return g
+}
+
 func (g *Group) MarshalJSON() ([]byte, error) {
 	return []byte(`"` + g.name + `"`), nil
 }
@@ -70,10 +146,17 @@ func hookBefore(ctx context.Context, desc Descriptor, input any, groups []*Group
 	for _, g := range groups {
 		var err error
 		for _, beforeHook := range g.hook.before {
-			ctx, err = beforeHook(ctx, desc, input)
+			var c context.Context
+			c, err = beforeHook(ctx, desc, input)
 			if err != nil {
+				// Hand the context of the hooks that succeeded to the error
+				// hooks, so that they can close what those hooks opened.
+				if c != nil {
+					ctx = c
+				}
 				return ctx, err
 			}
+			ctx = c
 		}
 	}
 	if ctx == nil {
@@ -99,3 +182,18 @@ func hookError(ctx context.Context, desc Descriptor, input any, err error, group
 		}
 	}
 }
+
+// hookMiddleware runs next wrapped by the middleware of groups, the first
+// group being the outermost.
+func hookMiddleware(ctx context.Context, desc Descriptor, input any, groups []*Group, next Next[any, any]) (any, error) {
+	for i := len(groups) - 1; i >= 0; i-- {
+		around := groups[i].hook.around
+		for j := len(around) - 1; j >= 0; j-- {
+			m, n := around[j], next
+			next = func(ctx context.Context, input any) (any, error) {
+				return m(ctx, desc, input, n)
+			}
+		}
+	}
+	return next(ctx, input)
+}
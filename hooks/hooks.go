@@ -2,14 +2,23 @@ package hooks
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"log/slog"
+	"unicode/utf8"
 
 	"github.com/ralsnet/grepo"
 )
 
 type HookSlogOptions struct {
-	level slog.Level
-	msg   string
+	level       slog.Level
+	msg         string
+	logger      *slog.Logger
+	redactor    Redactor
+	maxSize     int
+	duration    bool
+	executionID bool
+	errorCode   bool
 }
 
 type HookSlogOptionFunc func(*HookSlogOptions)
@@ -26,6 +35,53 @@ func WithSlogMsg(msg string) HookSlogOptionFunc {
 	}
 }
 
+// WithSlogLogger logs to logger instead of slog.Default().
+func WithSlogLogger(logger *slog.Logger) HookSlogOptionFunc {
+	return func(o *HookSlogOptions) {
+		o.logger = logger
+	}
+}
+
+// WithSlogMaxDepth replaces inputs and outputs nested deeper than depth with
+// Truncated.
+func WithSlogMaxDepth(depth int) HookSlogOptionFunc {
+	return func(o *HookSlogOptions) {
+		o.redactor.MaxDepth = depth
+	}
+}
+
+// WithSlogMaxSize logs inputs and outputs whose JSON encoding exceeds size
+// bytes as a string cut at that size.
+func WithSlogMaxSize(size int) HookSlogOptionFunc {
+	return func(o *HookSlogOptions) {
+		o.maxSize = size
+	}
+}
+
+// WithSlogDuration adds the time elapsed since the execution started as
+// "duration".
+func WithSlogDuration() HookSlogOptionFunc {
+	return func(o *HookSlogOptions) {
+		o.duration = true
+	}
+}
+
+// WithSlogExecutionID adds the ID of grepo.ExecutionInfo as "execution_id".
+func WithSlogExecutionID() HookSlogOptionFunc {
+	return func(o *HookSlogOptions) {
+		o.executionID = true
+	}
+}
+
+// WithSlogErrorCode adds the grepo.Code of the error as "error_code".
+func WithSlogErrorCode() HookSlogOptionFunc {
+	return func(o *HookSlogOptions) {
+		o.errorCode = true
+	}
+}
+
+// HookBeforeSlog logs the input, with fields tagged grepo:"sensitive"
+// redacted.
 func HookBeforeSlog(opts ...HookSlogOptionFunc) grepo.BeforeHook[any] {
 	options := &HookSlogOptions{
 		level: slog.LevelInfo,
@@ -35,11 +91,14 @@ func HookBeforeSlog(opts ...HookSlogOptionFunc) grepo.BeforeHook[any] {
 		opt(options)
 	}
 	return func(ctx context.Context, desc grepo.Descriptor, i any) (context.Context, error) {
-		slog.Log(ctx, options.level, options.msg, "operation", desc.Operation(), "input", i)
+		args := append(options.args(ctx, desc), "input", options.render(i))
+		options.log(ctx, args...)
 		return ctx, nil
 	}
 }
 
+// HookAfterSlog logs the output, with fields tagged grepo:"sensitive"
+// redacted.
 func HookAfterSlog(opts ...HookSlogOptionFunc) grepo.AfterHook[any, any] {
 	options := &HookSlogOptions{
 		level: slog.LevelInfo,
@@ -49,10 +108,13 @@ func HookAfterSlog(opts ...HookSlogOptionFunc) grepo.AfterHook[any, any] {
 		opt(options)
 	}
 	return func(ctx context.Context, desc grepo.Descriptor, i any, o any) {
-		slog.Log(ctx, options.level, options.msg, "operation", desc.Operation(), "output", o)
+		args := append(options.appendDuration(ctx, options.args(ctx, desc)), "output", options.render(o))
+		options.log(ctx, args...)
 	}
 }
 
+// HookErrorSlog logs the error and the input, with fields tagged
+// grepo:"sensitive" redacted.
 func HookErrorSlog(opts ...HookSlogOptionFunc) grepo.ErrorHook[any] {
 	options := &HookSlogOptions{
 		level: slog.LevelError,
@@ -62,6 +124,76 @@ func HookErrorSlog(opts ...HookSlogOptionFunc) grepo.ErrorHook[any] {
 		opt(options)
 	}
 	return func(ctx context.Context, desc grepo.Descriptor, i any, e error) {
-		slog.Log(ctx, options.level, options.msg, "operation", desc.Operation(), "input", i, "error", e)
+		args := append(options.appendDuration(ctx, options.args(ctx, desc)), "input", options.render(i), "error", e)
+		if options.errorCode {
+			args = append(args, "error_code", string(grepo.CodeOf(e)))
+		}
+		var perr *grepo.PanicError
+		if errors.As(e, &perr) {
+			args = append(args, "stack", string(perr.Stack))
+		}
+		options.log(ctx, args...)
+	}
+}
+
+// HookDeprecationSlog logs a warning for every call to a use case marked
+// WithDeprecated, with its deprecation message.
+func HookDeprecationSlog(opts ...HookSlogOptionFunc) grepo.BeforeHook[any] {
+	options := &HookSlogOptions{
+		level: slog.LevelWarn,
+		msg:   "Deprecated operation called",
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return func(ctx context.Context, desc grepo.Descriptor, i any) (context.Context, error) {
+		if m := grepo.MetadataOf(desc); m.IsDeprecated() {
+			args := append(options.args(ctx, desc), "deprecated", m.Deprecated)
+			if info := grepo.ExecutionInfoFrom(ctx); info != nil && info.Principal != nil {
+				args = append(args, "principal", info.Principal.ID)
+			}
+			options.log(ctx, args...)
+		}
+		return ctx, nil
+	}
+}
+
+func (o *HookSlogOptions) args(ctx context.Context, desc grepo.Descriptor) []any {
+	args := []any{"operation", desc.Operation()}
+	info := grepo.ExecutionInfoFrom(ctx)
+	if o.executionID && info != nil {
+		args = append(args, "execution_id", info.ID)
+	}
+	return args
+}
+
+func (o *HookSlogOptions) appendDuration(ctx context.Context, args []any) []any {
+	if d, ok := grepo.Elapsed(ctx); ok && o.duration {
+		args = append(args, "duration", d)
+	}
+	return args
+}
+
+func (o *HookSlogOptions) render(v any) any {
+	r := o.redactor.Redact(v)
+	if o.maxSize <= 0 {
+		return r
+	}
+	b, err := json.Marshal(r)
+	if err != nil || len(b) <= o.maxSize {
+		return r
+	}
+	n := o.maxSize
+	for n > 0 && !utf8.RuneStart(b[n]) {
+		n--
+	}
+	return string(b[:n]) + "..."
+}
+
+func (o *HookSlogOptions) log(ctx context.Context, args ...any) {
+	logger := o.logger
+	if logger == nil {
+		logger = slog.Default()
 	}
+	logger.Log(ctx, o.level, o.msg, args...)
 }
@@ -0,0 +1,201 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ralsnet/grepo"
+)
+
+type credentials struct {
+	User     string `json:"user"`
+	Password string `json:"password" grepo:"sensitive"`
+}
+
+type signUpInput struct {
+	Credentials credentials         `json:"credentials"`
+	Tokens      []credentials       `json:"tokens" grepo:"optional"`
+	Profile     map[string]*profile `json:"profile" grepo:"optional"`
+	Card        *profile            `json:"card" grepo:"sensitive;optional"`
+	JoinedAt    time.Time           `json:"joinedAt" grepo:"optional"`
+	Extra       any                 `json:"extra" grepo:"optional"`
+	Tags        map[string][]string `json:"tags" grepo:"optional"`
+}
+
+type profile struct {
+	Email string `json:"email" grepo:"sensitive"`
+	Bio   string `json:"bio"`
+}
+
+func TestRedactor(t *testing.T) {
+	in := signUpInput{
+		Credentials: credentials{User: "alice", Password: "p@ss"},
+		Tokens:      []credentials{{User: "bob", Password: "t0k"}},
+		Profile:     map[string]*profile{"main": {Email: "a@example.com", Bio: "hi"}},
+		Card:        &profile{Email: "card"},
+		JoinedAt:    time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		Extra:       &credentials{User: "carol", Password: "xyz"},
+		Tags:        map[string][]string{"a": {"b"}},
+	}
+
+	tests := []struct {
+		name     string
+		maxDepth int
+		want     string
+	}{
+		{
+			name: "正常系: 入れ子のsensitiveを伏せる",
+			want: `{"card":"[REDACTED]","credentials":{"password":"[REDACTED]","user":"alice"},"extra":{"password":"[REDACTED]","user":"carol"},"joinedAt":"2025-01-01T00:00:00Z","profile":{"main":{"bio":"hi","email":"[REDACTED]"}},"tags":{"a":["b"]},"tokens":[{"password":"[REDACTED]","user":"bob"}]}`,
+		},
+		{
+			name:     "正常系: 最大の深さ",
+			maxDepth: 2,
+			want:     `{"card":"[REDACTED]","credentials":{"password":"[REDACTED]","user":"alice"},"extra":{"password":"[REDACTED]","user":"carol"},"joinedAt":"2025-01-01T00:00:00Z","profile":{"main":"[TRUNCATED]"},"tags":{"a":"[TRUNCATED]"},"tokens":["[TRUNCATED]"]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Redactor{MaxDepth: tt.maxDepth}
+			b, err := json.Marshal(r.Redact(&in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != tt.want {
+				t.Errorf("Redact() = %s, want %s", b, tt.want)
+			}
+		})
+	}
+
+	t.Run("正常系: nilと値", func(t *testing.T) {
+		r := &Redactor{}
+		var p *credentials
+		if got := r.Redact(p); got != nil {
+			t.Errorf("Redact(nil pointer) = %v", got)
+		}
+		if got := r.Redact(42); got != 42 {
+			t.Errorf("Redact(42) = %v", got)
+		}
+	})
+}
+
+type signUpRequest struct {
+	Credentials credentials `json:"credentials"`
+	Note        string      `json:"note" grepo:"optional"`
+}
+
+type signUpOutput struct {
+	Token string `json:"token" grepo:"sensitive"`
+}
+
+type signUpUseCase struct{}
+
+func (u *signUpUseCase) Execute(ctx context.Context, in signUpRequest) (*signUpOutput, error) {
+	grepo.ClockFrom(ctx).(*grepo.FakeClock).Advance(250 * time.Millisecond)
+	if in.Credentials.User == "" {
+		return nil, grepo.NewError(grepo.CodeInvalid, "user is empty")
+	}
+	return &signUpOutput{Token: "secret-token"}, nil
+}
+
+func TestHookSlog(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	opts := []HookSlogOptionFunc{WithSlogLogger(logger), WithSlogDuration(), WithSlogExecutionID(), WithSlogErrorCode(), WithSlogMaxSize(60)}
+	api := grepo.NewAPIBuilder().
+		WithOptions(
+			grepo.WithClock(grepo.NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))),
+			grepo.WithIDGenerator(func() string { return "exec-1" }),
+		).
+		AddBeforeHook(HookBeforeSlog(opts...)).
+		AddAfterHook(HookAfterSlog(opts...)).
+		AddErrorHook(HookErrorSlog(opts...)).
+		AddUseCase(grepo.NewUseCaseBuilder(&signUpUseCase{}).WithOperation("SignUp").Build()).
+		Build()
+
+	tests := []struct {
+		name  string
+		input signUpRequest
+		want  []string
+	}{
+		{
+			name:  "正常系: 入出力を伏せて記録",
+			input: signUpRequest{Credentials: credentials{User: "alice", Password: "p@ss"}},
+			want: []string{
+				`{"level":"INFO","msg":"Starting operation","operation":"SignUp","execution_id":"exec-1","input":"{\"credentials\":{\"password\":\"[REDACTED]\",\"user\":\"alice\"},\"not..."}`,
+				`{"level":"INFO","msg":"Finished operation","operation":"SignUp","execution_id":"exec-1","duration":250000000,"output":{"token":"[REDACTED]"}}`,
+			},
+		},
+		{
+			name:  "異常系: エラーコードと経過時間",
+			input: signUpRequest{Credentials: credentials{Password: "p@ss"}},
+			want: []string{
+				`{"level":"INFO","msg":"Starting operation","operation":"SignUp","execution_id":"exec-1","input":"{\"credentials\":{\"password\":\"[REDACTED]\",\"user\":\"\"},\"note\":\"\"..."}`,
+				`{"level":"ERROR","msg":"Operation error","operation":"SignUp","execution_id":"exec-1","duration":250000000,"input":"{\"credentials\":{\"password\":\"[REDACTED]\",\"user\":\"\"},\"note\":\"\"...","error":"Invalid: user is empty","error_code":"Invalid"}`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			_, _ = api.ExecuteAny(context.Background(), "SignUp", tt.input)
+			got := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			if len(got) != len(tt.want) {
+				t.Fatalf("logs = %v", got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("logs[%d] = %s, want %s", i, got[i], tt.want[i])
+				}
+			}
+			if strings.Contains(buf.String(), "p@ss") || strings.Contains(buf.String(), "secret-token") {
+				t.Errorf("logs = %s", buf.String())
+			}
+		})
+	}
+}
+
+type legacyUseCase struct{}
+
+func (u *legacyUseCase) Execute(ctx context.Context, in signUpRequest) (*signUpOutput, error) {
+	return &signUpOutput{}, nil
+}
+
+func TestHookDeprecationSlog(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	api := grepo.NewAPIBuilder().
+		WithOptions(grepo.WithClock(grepo.NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)))).
+		AddBeforeHook(HookDeprecationSlog(WithSlogLogger(logger))).
+		AddUseCase(grepo.NewUseCaseBuilder(&signUpUseCase{}).WithOperation("SignUp").Build()).
+		AddUseCase(grepo.NewUseCaseBuilder(&legacyUseCase{}).WithOperation("Register").WithDeprecated("use SignUp").Build()).
+		Build()
+
+	ctx := grepo.WithPrincipal(context.Background(), &grepo.Principal{ID: "alice"})
+	for _, op := range []string{"SignUp", "Register"} {
+		_, _ = api.ExecuteAny(ctx, op, signUpRequest{Credentials: credentials{User: "alice"}})
+	}
+
+	want := `level=WARN msg="Deprecated operation called" operation=Register deprecated="use SignUp" principal=alice` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("logs = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,121 @@
+package hooks
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/ralsnet/grepo/refl"
+)
+
+const (
+	// Redacted replaces the value of fields tagged grepo:"sensitive".
+	Redacted = "[REDACTED]"
+	// Truncated replaces values nested deeper than Redactor.MaxDepth.
+	Truncated = "[TRUNCATED]"
+)
+
+var (
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+)
+
+// Redactor renders values for logging. Structs become maps keyed by their
+// JSON field names, with sensitive fields replaced by Redacted at any depth.
+// Types implementing json.Marshaler or encoding.TextMarshaler, such as
+// time.Time, are kept as they are.
+type Redactor struct {
+	// MaxDepth limits the nesting of structs, slices and maps. Zero means no
+	// limit.
+	MaxDepth int
+
+	types sync.Map
+}
+
+func (r *Redactor) Redact(v any) any {
+	if v == nil {
+		return nil
+	}
+	return r.redact(reflect.ValueOf(v), 0)
+}
+
+func (r *Redactor) redact(v reflect.Value, depth int) any {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		if v.Kind() == reflect.Pointer && marshals(v.Type()) && v.CanInterface() {
+			return v.Interface()
+		}
+		v = v.Elem()
+	}
+	if marshals(v.Type()) && v.CanInterface() {
+		return v.Interface()
+	}
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 && v.CanInterface() {
+		// Keep []byte as is so that it is logged like encoding/json does.
+		return v.Interface()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct, reflect.Slice, reflect.Array, reflect.Map:
+		if r.MaxDepth > 0 && depth >= r.MaxDepth {
+			return Truncated
+		}
+	default:
+		if !v.CanInterface() {
+			return nil
+		}
+		return v.Interface()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		fields := make(map[string]any)
+		for _, f := range r.typeOf(v.Type()).Fields {
+			fv, err := v.FieldByIndexErr(f.Index)
+			if err != nil {
+				continue
+			}
+			if f.Sensitive {
+				fields[f.Name] = Redacted
+				continue
+			}
+			fields[f.Name] = r.redact(fv, depth+1)
+		}
+		return fields
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		entries := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			entries[fmt.Sprint(iter.Key().Interface())] = r.redact(iter.Value(), depth+1)
+		}
+		return entries
+	default:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		items := make([]any, v.Len())
+		for i := range items {
+			items[i] = r.redact(v.Index(i), depth+1)
+		}
+		return items
+	}
+}
+
+func (r *Redactor) typeOf(rt reflect.Type) *refl.Type {
+	if t, ok := r.types.Load(rt); ok {
+		return t.(*refl.Type)
+	}
+	t, _ := r.types.LoadOrStore(rt, refl.TypeFor(rt))
+	return t.(*refl.Type)
+}
+
+func marshals(rt reflect.Type) bool {
+	return rt.Implements(textMarshalerType) || rt.Implements(jsonMarshalerType)
+}
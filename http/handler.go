@@ -0,0 +1,222 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/ralsnet/grepo"
+	"github.com/ralsnet/grepo/tracing"
+)
+
+const (
+	SpecPath          = "/spec"
+	TraceparentHeader = "traceparent"
+)
+
+type HandlerOptions struct {
+	prefix        string
+	maxBodySize   int64
+	authenticator Authenticator
+}
+
+// Authenticator identifies the caller of a request. It returns a nil
+// Principal for anonymous requests and an error to reject the request, which
+// is reported as Unauthenticated unless it carries another code.
+type Authenticator func(r *http.Request) (*grepo.Principal, error)
+
+type HandlerOptionFunc func(*HandlerOptions)
+
+func WithPrefix(prefix string) HandlerOptionFunc {
+	return func(o *HandlerOptions) {
+		o.prefix = "/" + strings.Trim(prefix, "/")
+	}
+}
+
+func WithMaxBodySize(n int64) HandlerOptionFunc {
+	return func(o *HandlerOptions) {
+		o.maxBodySize = n
+	}
+}
+
+func WithAuthenticator(fn Authenticator) HandlerOptionFunc {
+	return func(o *HandlerOptions) {
+		o.authenticator = fn
+	}
+}
+
+type ErrorResponse struct {
+	Error      string         `json:"error"`
+	Code       grepo.Code     `json:"code,omitempty"`
+	Details    map[string]any `json:"details,omitempty"`
+	Violations []Violation    `json:"violations,omitempty"`
+}
+
+type Violation struct {
+	Path       string `json:"path"`
+	Constraint string `json:"constraint"`
+	Value      any    `json:"value,omitempty"`
+	Message    string `json:"message"`
+}
+
+func New(api *grepo.API, opts ...HandlerOptionFunc) *http.ServeMux {
+	options := &HandlerOptions{
+		maxBodySize: 1 << 20,
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+	prefix := strings.TrimSuffix(options.prefix, "/")
+
+	mux := http.NewServeMux()
+	for _, uc := range api.UseCases() {
+		mux.Handle("POST "+prefix+"/"+uc.Operation(), newUseCaseHandler(api, uc, options))
+	}
+	mux.Handle("GET "+prefix+SpecPath, specHandler(api))
+
+	return mux
+}
+
+func newUseCaseHandler(api *grepo.API, uc grepo.Descriptor, options *HandlerOptions) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := grepo.WithTransport(r.Context(), grepo.TransportHTTP)
+		if sc, err := tracing.ParseTraceparent(r.Header.Get(TraceparentHeader)); err == nil {
+			ctx = tracing.ContextWithRemoteSpanContext(ctx, sc)
+		}
+		if options.authenticator != nil {
+			principal, err := options.authenticator(r)
+			if err != nil {
+				if grepo.CodeOf(err) == grepo.CodeUnknown {
+					err = grepo.WrapError(grepo.CodeUnauthenticated, err, "unauthenticated")
+				}
+				writeError(w, StatusOf(err), err)
+				return
+			}
+			ctx = grepo.WithPrincipal(ctx, principal)
+		}
+
+		input, err := getInput(w, r, uc, options)
+		if err != nil {
+			status := http.StatusBadRequest
+			var merr *http.MaxBytesError
+			if errors.As(err, &merr) {
+				status = http.StatusRequestEntityTooLarge
+			}
+			writeError(w, status, err)
+			return
+		}
+
+		output, err := api.ExecuteAny(ctx, uc.Operation(), input)
+		if err != nil {
+			writeError(w, StatusOf(err), err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, output)
+	}
+}
+
+func specHandler(api *grepo.API) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, api)
+	}
+}
+
+func getInput(w http.ResponseWriter, r *http.Request, uc grepo.Descriptor, options *HandlerOptions) (any, error) {
+	body := r.Body
+	if options.maxBodySize > 0 {
+		body = http.MaxBytesReader(w, body, options.maxBodySize)
+	}
+	b, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	if len(strings.TrimSpace(string(b))) == 0 {
+		b = []byte("{}")
+	}
+
+	p := reflect.New(reflect.TypeOf(uc.Input())).Interface()
+	if err := json.Unmarshal(b, p); err != nil {
+		return nil, err
+	}
+
+	return reflect.ValueOf(p).Elem().Interface(), nil
+}
+
+func StatusOf(err error) int {
+	return StatusOfCode(grepo.CodeOf(err))
+}
+
+func StatusOfCode(code grepo.Code) int {
+	switch code {
+	case grepo.CodeNotFound:
+		return http.StatusNotFound
+	case grepo.CodeInvalid:
+		return http.StatusBadRequest
+	case grepo.CodeConflict:
+		return http.StatusConflict
+	case grepo.CodeUnauthenticated:
+		return http.StatusUnauthorized
+	case grepo.CodePermissionDenied:
+		return http.StatusForbidden
+	case grepo.CodeUnavailable:
+		return http.StatusServiceUnavailable
+	case grepo.CodeDeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case grepo.CodeCanceled:
+		// Non-standard, but widely used for requests the client abandoned.
+		return 499
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	res := ErrorResponse{Error: err.Error()}
+	if code := grepo.CodeOf(err); code != grepo.CodeUnknown {
+		res.Code = code
+	}
+	var gerr *grepo.Error
+	if errors.As(err, &gerr) {
+		// Only the message is meant for callers; the cause stays server side.
+		res.Error = gerr.Message
+		if res.Error == "" {
+			res.Error = string(gerr.Code)
+		}
+		res.Details = gerr.Details
+	}
+	var verr *grepo.ValidationError
+	if errors.As(err, &verr) {
+		res.Error = grepo.ErrInvalid.Error()
+		for _, v := range verr.Violations {
+			res.Violations = append(res.Violations, Violation{
+				Path:       v.Path,
+				Constraint: v.Constraint,
+				Value:      v.Value,
+				Message:    v.Message,
+			})
+		}
+	}
+	if status >= http.StatusInternalServerError {
+		// Server errors, such as an output that failed validation, say
+		// nothing about the request, so none of their details are exposed.
+		res.Error = http.StatusText(status)
+		res.Details = nil
+		res.Violations = nil
+	}
+	writeJSON(w, status, res)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		status = http.StatusInternalServerError
+		b, _ = json.Marshal(ErrorResponse{Error: http.StatusText(status)})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(b)
+}
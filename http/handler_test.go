@@ -0,0 +1,291 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ralsnet/grepo"
+	"github.com/ralsnet/grepo/tracing"
+)
+
+type testInput struct {
+	Value int    `json:"value"`
+	Kind  string `json:"kind" grepo:"enum:a,b"`
+}
+
+type testOutput struct {
+	Result int `json:"result"`
+}
+
+type addOneUseCase struct{}
+
+func (u *addOneUseCase) Execute(ctx context.Context, input testInput) (*testOutput, error) {
+	return &testOutput{Result: input.Value + 1}, nil
+}
+
+type notFoundUseCase struct{}
+
+func (u *notFoundUseCase) Execute(ctx context.Context, input testInput) (*testOutput, error) {
+	return nil, errors.Join(grepo.ErrNotFound, errors.New("missing"))
+}
+
+type conflictUseCase struct{}
+
+func (u *conflictUseCase) Execute(ctx context.Context, input testInput) (*testOutput, error) {
+	return nil, grepo.WrapError(grepo.CodeConflict, errors.New("duplicate key"), "already exists").WithDetail("value", input.Value)
+}
+
+type internalUseCase struct{}
+
+func (u *internalUseCase) Execute(ctx context.Context, input testInput) (*testOutput, error) {
+	return nil, grepo.WrapError(grepo.CodeInternal, errors.New("connection refused"), "storage failure")
+}
+
+type positiveOutput struct {
+	Result int `json:"result" grepo:"min:1"`
+}
+
+type invalidOutputUseCase struct{}
+
+func (u *invalidOutputUseCase) Execute(ctx context.Context, input testInput) (*positiveOutput, error) {
+	return &positiveOutput{Result: -input.Value}, nil
+}
+
+func newTestAPI() *grepo.API {
+	return grepo.NewAPIBuilder().
+		WithOptions(grepo.WithEnableInputValidation(), grepo.WithEnableOutputValidation()).
+		AddUseCase(grepo.NewUseCaseBuilder(&addOneUseCase{}).WithOperation("add_one").Build()).
+		AddUseCase(grepo.NewUseCaseBuilder(&notFoundUseCase{}).WithOperation("not_found").Build()).
+		AddUseCase(grepo.NewUseCaseBuilder(&conflictUseCase{}).WithOperation("conflict").Build()).
+		AddUseCase(grepo.NewUseCaseBuilder(&internalUseCase{}).WithOperation("internal").Build()).
+		AddUseCase(grepo.NewUseCaseBuilder(&invalidOutputUseCase{}).WithOperation("invalid_output").Build()).
+		Build()
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       []HandlerOptionFunc
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "正常系: ユースケース実行",
+			method:     http.MethodPost,
+			path:       "/add_one",
+			body:       `{"value":1,"kind":"a"}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"result":2}`,
+		},
+		{
+			name:       "正常系: プレフィックス付き",
+			opts:       []HandlerOptionFunc{WithPrefix("api")},
+			method:     http.MethodPost,
+			path:       "/api/add_one",
+			body:       `{"value":1,"kind":"b"}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"result":2}`,
+		},
+		{
+			name:       "異常系: バリデーションエラー",
+			method:     http.MethodPost,
+			path:       "/add_one",
+			body:       `{"value":1,"kind":"c"}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":"Invalid","code":"Invalid","violations":[{"path":"kind","constraint":"enum","value":"c","message":"has value c which is not in enum [a b]"}]}`,
+		},
+		{
+			name:       "異常系: 不正なJSON",
+			method:     http.MethodPost,
+			path:       "/add_one",
+			body:       `{`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "異常系: 上限を超えるボディ",
+			opts:       []HandlerOptionFunc{WithMaxBodySize(8)},
+			method:     http.MethodPost,
+			path:       "/add_one",
+			body:       `{"value":1,"kind":"a"}`,
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantBody:   `{"error":"http: request body too large"}`,
+		},
+		{
+			name:       "正常系: 上限以内のボディ",
+			opts:       []HandlerOptionFunc{WithMaxBodySize(64)},
+			method:     http.MethodPost,
+			path:       "/add_one",
+			body:       `{"value":1,"kind":"a"}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"result":2}`,
+		},
+		{
+			name:       "異常系: ユースケースがErrNotFoundを返す",
+			method:     http.MethodPost,
+			path:       "/not_found",
+			body:       `{"value":1,"kind":"a"}`,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "異常系: grepo.Errorのメッセージと詳細を返す",
+			method:     http.MethodPost,
+			path:       "/conflict",
+			body:       `{"value":1,"kind":"a"}`,
+			wantStatus: http.StatusConflict,
+			wantBody:   `{"error":"already exists","code":"Conflict","details":{"value":1}}`,
+		},
+		{
+			name:       "異常系: 5xxはメッセージを隠す",
+			method:     http.MethodPost,
+			path:       "/internal",
+			body:       `{"value":1,"kind":"a"}`,
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `{"error":"Internal Server Error","code":"Internal"}`,
+		},
+		{
+			name:       "異常系: 出力のバリデーションエラーは違反内容を隠して500を返す",
+			method:     http.MethodPost,
+			path:       "/invalid_output",
+			body:       `{"value":1,"kind":"a"}`,
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `{"error":"Internal Server Error","code":"Internal"}`,
+		},
+		{
+			name:       "異常系: 存在しないオペレーション",
+			method:     http.MethodPost,
+			path:       "/not_exists",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "異常系: メソッド不一致",
+			method:     http.MethodGet,
+			path:       "/add_one",
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := New(newTestAPI(), tt.opts...)
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %v, want %v (body: %s)", rec.Code, tt.wantStatus, rec.Body.String())
+				return
+			}
+			if tt.wantBody != "" && rec.Body.String() != tt.wantBody {
+				t.Errorf("body = %v, want %v", rec.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestNew_Spec(t *testing.T) {
+	api := newTestAPI()
+	h := New(api)
+	req := httptest.NewRequest(http.MethodGet, SpecPath, nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %v, want %v", rec.Code, http.StatusOK)
+	}
+	want, _ := json.Marshal(api)
+	if rec.Body.String() != string(want) {
+		t.Errorf("body = %v, want %v", rec.Body.String(), string(want))
+	}
+}
+
+type whoamiInput struct{}
+
+type whoamiOutput struct {
+	Principal string `json:"principal"`
+	Transport string `json:"transport"`
+}
+
+type whoamiUseCase struct{}
+
+func (u *whoamiUseCase) Execute(ctx context.Context, input whoamiInput) (*whoamiOutput, error) {
+	info := grepo.ExecutionInfoFrom(ctx)
+	out := &whoamiOutput{Transport: info.Transport}
+	if info.Principal != nil {
+		out.Principal = info.Principal.ID
+	}
+	return out, nil
+}
+
+func TestNew_Authenticator(t *testing.T) {
+	api := grepo.NewAPIBuilder().
+		AddUseCase(grepo.NewUseCaseBuilder(&whoamiUseCase{}).WithOperation("whoami").Build()).
+		Build()
+	authenticate := func(r *http.Request) (*grepo.Principal, error) {
+		switch token := r.Header.Get("Authorization"); token {
+		case "":
+			return nil, nil
+		case "Bearer valid":
+			return &grepo.Principal{ID: "user-1"}, nil
+		default:
+			return nil, errors.New("invalid token")
+		}
+	}
+
+	tests := []struct {
+		name       string
+		opts       []HandlerOptionFunc
+		token      string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "正常系: 認証なし", wantStatus: http.StatusOK, wantBody: `{"principal":"","transport":"http"}`},
+		{name: "正常系: 匿名", opts: []HandlerOptionFunc{WithAuthenticator(authenticate)}, wantStatus: http.StatusOK, wantBody: `{"principal":"","transport":"http"}`},
+		{name: "正常系: 認証済み", opts: []HandlerOptionFunc{WithAuthenticator(authenticate)}, token: "Bearer valid", wantStatus: http.StatusOK, wantBody: `{"principal":"user-1","transport":"http"}`},
+		{name: "異常系: 認証エラー", opts: []HandlerOptionFunc{WithAuthenticator(authenticate)}, token: "Bearer invalid", wantStatus: http.StatusUnauthorized, wantBody: `{"error":"unauthenticated","code":"Unauthenticated"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/whoami", nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", tt.token)
+			}
+			rec := httptest.NewRecorder()
+			New(api, tt.opts...).ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus || rec.Body.String() != tt.wantBody {
+				t.Errorf("response = %v %s, want %v %s", rec.Code, rec.Body.String(), tt.wantStatus, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestNew_Traceparent(t *testing.T) {
+	recorder := tracing.NewRecorder()
+	api := grepo.NewAPIBuilder().
+		AddBeforeHook(tracing.HookBefore(recorder)).
+		AddAfterHook(tracing.HookAfter()).
+		AddErrorHook(tracing.HookError(recorder)).
+		AddUseCase(grepo.NewUseCaseBuilder(&whoamiUseCase{}).WithOperation("whoami").Build()).
+		Build()
+
+	req := httptest.NewRequest(http.MethodPost, "/whoami", nil)
+	req.Header.Set(TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	rec := httptest.NewRecorder()
+	New(api).ServeHTTP(rec, req)
+
+	spans := recorder.Spans()
+	if rec.Code != http.StatusOK || len(spans) != 1 {
+		t.Fatalf("status = %v, spans = %+v", rec.Code, spans)
+	}
+	if got := spans[0].Parent.SpanID.String(); got != "00f067aa0ba902b7" {
+		t.Errorf("parent span = %v", got)
+	}
+}
@@ -0,0 +1,48 @@
+package grepo
+
+// Example is a sample execution of a use case, for documentation.
+type Example struct {
+	Name   string
+	Input  any
+	Output any
+}
+
+// Metadata describes a use case beyond its input and output types.
+type Metadata struct {
+	Tags []string `json:",omitempty"`
+	// Deprecated explains what to use instead. It is empty unless the use case
+	// is deprecated.
+	Deprecated   string    `json:",omitempty"`
+	ReadOnly     bool      `json:",omitempty"`
+	Idempotent   bool      `json:",omitempty"`
+	Experimental bool      `json:",omitempty"`
+	Examples     []Example `json:",omitempty"`
+}
+
+// IsDeprecated reports whether the use case is deprecated.
+func (m Metadata) IsDeprecated() bool {
+	return m.Deprecated != ""
+}
+
+// MetadataOf returns the metadata d declares. Descriptors that do not
+// implement the corresponding methods have zero metadata.
+func MetadataOf(d Descriptor) Metadata {
+	var m Metadata
+	if t, ok := d.(interface{ Tags() []string }); ok {
+		m.Tags = t.Tags()
+	}
+	if t, ok := d.(interface{ Deprecated() string }); ok {
+		m.Deprecated = t.Deprecated()
+	}
+	if t, ok := d.(interface{ ReadOnly() bool }); ok {
+		m.ReadOnly = t.ReadOnly()
+	}
+	m.Idempotent = isIdempotent(d)
+	if t, ok := d.(interface{ Experimental() bool }); ok {
+		m.Experimental = t.Experimental()
+	}
+	if t, ok := d.(interface{ Examples() []Example }); ok {
+		m.Examples = t.Examples()
+	}
+	return m
+}
@@ -0,0 +1,62 @@
+package grepo
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestMetadataOf(t *testing.T) {
+	tests := []struct {
+		name     string
+		d        Descriptor
+		want     string
+		wantSpec string
+	}{
+		{
+			name:     "正常系: メタデータなし",
+			d:        NewUseCaseBuilder(&addOneUseCase{}).Build(),
+			want:     `{}`,
+			wantSpec: `"Groups":[]}`,
+		},
+		{
+			name: "正常系: 全てのメタデータ",
+			d: NewUseCaseBuilder(&addOneUseCase{}).
+				WithTags("math", "demo").
+				WithDeprecated("").
+				WithReadOnly().
+				WithExperimental().
+				AddExample("one", TestInput{Value: 1}, TestOutput{Result: 2}).
+				Build(),
+			want:     `{"Tags":["math","demo"],"Deprecated":"deprecated","ReadOnly":true,"Idempotent":true,"Experimental":true,"Examples":[{"Name":"one","Input":{"value":1},"Output":{"result":2}}]}`,
+			wantSpec: `"Groups":[],"Tags":["math","demo"],"Deprecated":"deprecated","ReadOnly":true,"Idempotent":true,"Experimental":true,"Examples":[{"Name":"one","Input":{"value":1},"Output":{"result":2}}]}`,
+		},
+		{
+			name: "正常系: メタデータを持たないDescriptor",
+			d:    &reflectDescriptor{d: NewUseCaseBuilder[benchInput, benchOutput](&benchUseCase{}).WithDeprecated("old").Build()},
+			want: `{}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := json.Marshal(MetadataOf(tt.d))
+			if string(got) != tt.want {
+				t.Errorf("MetadataOf() = %s, want %s", got, tt.want)
+			}
+			if tt.wantSpec == "" {
+				return
+			}
+			spec, err := json.Marshal(tt.d)
+			if err != nil {
+				t.Fatalf("MarshalJSON() error = %v", err)
+			}
+			if s := string(spec); len(s) < len(tt.wantSpec) || s[len(s)-len(tt.wantSpec):] != tt.wantSpec {
+				t.Errorf("MarshalJSON() = %s, want suffix %s", spec, tt.wantSpec)
+			}
+		})
+	}
+
+	if m := MetadataOf(NewUseCaseBuilder(&addOneUseCase{}).WithDeprecated("use v2").Build()); !m.IsDeprecated() || m.Deprecated != "use v2" {
+		t.Errorf("MetadataOf() = %s", fmt.Sprint(m))
+	}
+}
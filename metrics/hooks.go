@@ -0,0 +1,41 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ralsnet/grepo"
+)
+
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+)
+
+// HookAfter counts a successful execution and observes its latency, measured
+// from grepo.ExecutionInfo.StartTime.
+func HookAfter(r *Registry) grepo.AfterHook[any, any] {
+	return func(ctx context.Context, desc grepo.Descriptor, i any, o any) {
+		d, ok := grepo.Elapsed(ctx)
+		r.observe(seriesOf(desc), "", d, ok)
+	}
+}
+
+// HookError counts a failed execution by its error code and observes its
+// latency, including executions denied by the authorizer.
+func HookError(r *Registry) grepo.ErrorHook[any] {
+	return func(ctx context.Context, desc grepo.Descriptor, i any, err error) {
+		d, ok := grepo.Elapsed(ctx)
+		r.observe(seriesOf(desc), grepo.CodeOf(err), d, ok)
+	}
+}
+
+// seriesOf labels an execution by its operation and by its group names,
+// joined with "/" in the order they were added.
+func seriesOf(desc grepo.Descriptor) series {
+	groups := make([]string, 0, len(desc.Groups()))
+	for _, g := range desc.Groups() {
+		groups = append(groups, g.Name())
+	}
+	return series{operation: desc.Operation(), group: strings.Join(groups, "/")}
+}
@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ralsnet/grepo"
+)
+
+type input struct {
+	Value int
+}
+
+type output struct {
+	Value int
+}
+
+type getUseCase struct{}
+
+func (u *getUseCase) Execute(ctx context.Context, in input) (*output, error) {
+	grepo.ClockFrom(ctx).(*grepo.FakeClock).Advance(300 * time.Millisecond)
+	if in.Value < 0 {
+		return nil, grepo.NewError(grepo.CodeNotFound, "not found")
+	}
+	return &output{Value: in.Value}, nil
+}
+
+type protectedUseCase struct{}
+
+func (u *protectedUseCase) Execute(ctx context.Context, in input) (*output, error) {
+	return &output{}, nil
+}
+
+func TestHooks(t *testing.T) {
+	registry := NewRegistry(WithBuckets(1, 0.5))
+	api := grepo.NewAPIBuilder().
+		WithOptions(grepo.WithClock(grepo.NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)))).
+		AddAfterHook(HookAfter(registry)).
+		AddErrorHook(HookError(registry)).
+		AddUseCase(grepo.NewUseCaseBuilder(&getUseCase{}).
+			WithOperation("get").
+			WithGroup(grepo.NewGroup("users")).
+			WithGroup(grepo.NewGroup("read")).
+			Build()).
+		AddUseCase(grepo.NewUseCaseBuilder(&protectedUseCase{}).
+			WithOperation("protected").
+			WithRoles("admin").
+			Build()).
+		Build()
+
+	ctx := context.Background()
+	for _, v := range []int{1, 2, -1} {
+		_, _ = api.ExecuteAny(ctx, "get", input{Value: v})
+	}
+	_, _ = api.ExecuteAny(ctx, "protected", input{})
+
+	var b strings.Builder
+	if err := registry.WritePrometheus(&b); err != nil {
+		t.Fatalf("WritePrometheus() error = %v", err)
+	}
+	want := `# HELP grepo_operation_calls_total Number of finished executions.
+# TYPE grepo_operation_calls_total counter
+grepo_operation_calls_total{operation="get",group="users/read"} 3
+grepo_operation_calls_total{operation="protected",group=""} 1
+# HELP grepo_operation_errors_total Number of failed executions by error code.
+# TYPE grepo_operation_errors_total counter
+grepo_operation_errors_total{operation="get",group="users/read",code="NotFound"} 1
+grepo_operation_errors_total{operation="protected",group="",code="Unauthenticated"} 1
+# HELP grepo_operation_duration_seconds Execution latency in seconds.
+# TYPE grepo_operation_duration_seconds histogram
+grepo_operation_duration_seconds_bucket{operation="get",group="users/read",outcome="error",le="0.5"} 1
+grepo_operation_duration_seconds_bucket{operation="get",group="users/read",outcome="error",le="1"} 1
+grepo_operation_duration_seconds_bucket{operation="get",group="users/read",outcome="error",le="+Inf"} 1
+grepo_operation_duration_seconds_sum{operation="get",group="users/read",outcome="error"} 0.3
+grepo_operation_duration_seconds_count{operation="get",group="users/read",outcome="error"} 1
+grepo_operation_duration_seconds_bucket{operation="get",group="users/read",outcome="success",le="0.5"} 2
+grepo_operation_duration_seconds_bucket{operation="get",group="users/read",outcome="success",le="1"} 2
+grepo_operation_duration_seconds_bucket{operation="get",group="users/read",outcome="success",le="+Inf"} 2
+grepo_operation_duration_seconds_sum{operation="get",group="users/read",outcome="success"} 0.6
+grepo_operation_duration_seconds_count{operation="get",group="users/read",outcome="success"} 2
+grepo_operation_duration_seconds_bucket{operation="protected",group="",outcome="error",le="0.5"} 1
+grepo_operation_duration_seconds_bucket{operation="protected",group="",outcome="error",le="1"} 1
+grepo_operation_duration_seconds_bucket{operation="protected",group="",outcome="error",le="+Inf"} 1
+grepo_operation_duration_seconds_sum{operation="protected",group="",outcome="error"} 0
+grepo_operation_duration_seconds_count{operation="protected",group="",outcome="error"} 1
+`
+	if got := b.String(); got != want {
+		t.Errorf("WritePrometheus() =\n%s\nwant\n%s", got, want)
+	}
+}
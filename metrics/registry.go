@@ -0,0 +1,233 @@
+package metrics
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ralsnet/grepo"
+)
+
+// ContentType is the media type of the Prometheus text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultBuckets are the upper bounds, in seconds, of the latency histogram.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type RegistryOptions struct {
+	namespace string
+	buckets   []float64
+}
+
+type RegistryOptionFunc func(*RegistryOptions)
+
+// WithNamespace replaces the "grepo" prefix of the metric names.
+func WithNamespace(namespace string) RegistryOptionFunc {
+	return func(o *RegistryOptions) {
+		o.namespace = namespace
+	}
+}
+
+func WithBuckets(buckets ...float64) RegistryOptionFunc {
+	return func(o *RegistryOptions) {
+		o.buckets = slices.Sorted(slices.Values(buckets))
+	}
+}
+
+type series struct {
+	operation string
+	group     string
+}
+
+type errorSeries struct {
+	series
+	code grepo.Code
+}
+
+type durationSeries struct {
+	series
+	outcome string
+}
+
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// Registry holds the metrics recorded by the hooks of this package in
+// memory. It is safe for concurrent use.
+type Registry struct {
+	options   RegistryOptions
+	mu        sync.Mutex
+	calls     map[series]uint64
+	errors    map[errorSeries]uint64
+	durations map[durationSeries]*histogram
+}
+
+func NewRegistry(opts ...RegistryOptionFunc) *Registry {
+	options := RegistryOptions{
+		namespace: "grepo",
+		buckets:   DefaultBuckets,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return &Registry{
+		options:   options,
+		calls:     make(map[series]uint64),
+		errors:    make(map[errorSeries]uint64),
+		durations: make(map[durationSeries]*histogram),
+	}
+}
+
+func (r *Registry) observe(s series, code grepo.Code, d time.Duration, measured bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls[s]++
+	outcome := OutcomeSuccess
+	if code != "" {
+		r.errors[errorSeries{series: s, code: code}]++
+		outcome = OutcomeError
+	}
+	if !measured {
+		return
+	}
+
+	ds := durationSeries{series: s, outcome: outcome}
+	h, ok := r.durations[ds]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(r.options.buckets))}
+		r.durations[ds] = h
+	}
+	v := d.Seconds()
+	for i, le := range r.options.buckets {
+		if v <= le {
+			h.counts[i]++
+		}
+	}
+	h.sum += v
+	h.count++
+}
+
+func (r *Registry) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	clear(r.calls)
+	clear(r.errors)
+	clear(r.durations)
+}
+
+// WritePrometheus writes the current values in the Prometheus text exposition
+// format. Series are sorted so that the output is stable.
+func (r *Registry) WritePrometheus(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+	ns := r.options.namespace
+
+	name := ns + "_operation_calls_total"
+	writeHeader(bw, name, "counter", "Number of finished executions.")
+	for _, s := range sortedKeys(r.calls, compareSeries) {
+		fmt.Fprintf(bw, "%s{%s} %d\n", name, s.labels(), r.calls[s])
+	}
+
+	name = ns + "_operation_errors_total"
+	writeHeader(bw, name, "counter", "Number of failed executions by error code.")
+	for _, s := range sortedKeys(r.errors, func(a, b errorSeries) int {
+		return cmp.Or(compareSeries(a.series, b.series), cmp.Compare(a.code, b.code))
+	}) {
+		fmt.Fprintf(bw, "%s{%s,%s} %d\n", name, s.labels(), label("code", string(s.code)), r.errors[s])
+	}
+
+	name = ns + "_operation_duration_seconds"
+	writeHeader(bw, name, "histogram", "Execution latency in seconds.")
+	for _, s := range sortedKeys(r.durations, func(a, b durationSeries) int {
+		return cmp.Or(compareSeries(a.series, b.series), cmp.Compare(a.outcome, b.outcome))
+	}) {
+		h := r.durations[s]
+		labels := s.labels() + "," + label("outcome", s.outcome)
+		for i, le := range r.options.buckets {
+			fmt.Fprintf(bw, "%s_bucket{%s,%s} %d\n", name, labels, label("le", formatFloat(le)), h.counts[i])
+		}
+		fmt.Fprintf(bw, "%s_bucket{%s,%s} %d\n", name, labels, label("le", "+Inf"), h.count)
+		fmt.Fprintf(bw, "%s_sum{%s} %s\n", name, labels, formatFloat(h.sum))
+		fmt.Fprintf(bw, "%s_count{%s} %d\n", name, labels, h.count)
+	}
+
+	return bw.Flush()
+}
+
+// ServeHTTP exposes the metrics for scraping, typically on GET /metrics.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+	_ = r.WritePrometheus(w)
+}
+
+// WriteFile writes the metrics to path through a temporary file and a
+// rename, so that a reader such as the node_exporter textfile collector never
+// sees a partial file. It suits batch jobs that exit before any scrape. The
+// file is readable by all users, as the collector often runs as another one.
+func (r *Registry) WriteFile(path string) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if err := r.WritePrometheus(f); err != nil {
+		f.Close()
+		return err
+	}
+	// CreateTemp creates the file with mode 0600.
+	if err := f.Chmod(0o644); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+func (s series) labels() string {
+	return label("operation", s.operation) + "," + label("group", s.group)
+}
+
+func compareSeries(a, b series) int {
+	return cmp.Or(cmp.Compare(a.operation, b.operation), cmp.Compare(a.group, b.group))
+}
+
+func sortedKeys[K comparable, V any](m map[K]V, compare func(a, b K) int) []K {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, compare)
+	return keys
+}
+
+func writeHeader(w io.Writer, name, typ, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func label(name, value string) string {
+	return name + `="` + labelEscaper.Replace(value) + `"`
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ralsnet/grepo"
+)
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry(WithNamespace("app"), WithBuckets(0.1))
+	registry.observe(series{operation: "op\"1\"", group: `a\b`}, grepo.CodeInternal, 50*time.Millisecond, true)
+
+	want := `# HELP app_operation_calls_total Number of finished executions.
+# TYPE app_operation_calls_total counter
+app_operation_calls_total{operation="op\"1\"",group="a\\b"} 1
+# HELP app_operation_errors_total Number of failed executions by error code.
+# TYPE app_operation_errors_total counter
+app_operation_errors_total{operation="op\"1\"",group="a\\b",code="Internal"} 1
+# HELP app_operation_duration_seconds Execution latency in seconds.
+# TYPE app_operation_duration_seconds histogram
+app_operation_duration_seconds_bucket{operation="op\"1\"",group="a\\b",outcome="error",le="0.1"} 1
+app_operation_duration_seconds_bucket{operation="op\"1\"",group="a\\b",outcome="error",le="+Inf"} 1
+app_operation_duration_seconds_sum{operation="op\"1\"",group="a\\b",outcome="error"} 0.05
+app_operation_duration_seconds_count{operation="op\"1\"",group="a\\b",outcome="error"} 1
+`
+
+	t.Run("正常系: HTTPハンドラ", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		registry.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		if ct := rec.Header().Get("Content-Type"); ct != ContentType {
+			t.Errorf("Content-Type = %q", ct)
+		}
+		if got := rec.Body.String(); got != want {
+			t.Errorf("body =\n%s\nwant\n%s", got, want)
+		}
+	})
+
+	t.Run("正常系: ファイル出力", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "app.prom")
+		if err := registry.WriteFile(path); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != want {
+			t.Errorf("file =\n%s\nwant\n%s", b, want)
+		}
+		entries, _ := os.ReadDir(dir)
+		if len(entries) != 1 {
+			t.Errorf("entries = %v", entries)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0o644 {
+			t.Errorf("mode = %v, want -rw-r--r--", info.Mode())
+		}
+	})
+
+	t.Run("正常系: リセット", func(t *testing.T) {
+		registry.Reset()
+		var b strings.Builder
+		_ = registry.WritePrometheus(&b)
+		if strings.Contains(b.String(), "Internal") {
+			t.Errorf("WritePrometheus() = %s", b.String())
+		}
+	})
+}
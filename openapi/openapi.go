@@ -0,0 +1,240 @@
+package openapi
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ralsnet/grepo"
+	grepohttp "github.com/ralsnet/grepo/http"
+	"github.com/ralsnet/grepo/refl"
+	"github.com/ralsnet/grepo/schema"
+)
+
+const Version = "3.1.0"
+
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Servers    []Server             `json:"servers,omitempty"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components *Components          `json:"components,omitempty"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type Server struct {
+	URL string `json:"url"`
+}
+
+type PathItem struct {
+	Post *Operation `json:"post,omitempty"`
+}
+
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	Summary     string               `json:"summary,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Deprecated  bool                 `json:"deprecated,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema   *Schema             `json:"schema"`
+	Examples map[string]*Example `json:"examples,omitempty"`
+}
+
+type Example struct {
+	Summary string `json:"summary,omitempty"`
+	Value   any    `json:"value"`
+}
+
+type Schema = schema.Schema
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas,omitempty"`
+}
+
+type Options struct {
+	title   string
+	version string
+	prefix  string
+	servers []Server
+}
+
+type OptionFunc func(*Options)
+
+func WithTitle(title string) OptionFunc {
+	return func(o *Options) {
+		o.title = title
+	}
+}
+
+func WithVersion(version string) OptionFunc {
+	return func(o *Options) {
+		o.version = version
+	}
+}
+
+func WithPrefix(prefix string) OptionFunc {
+	return func(o *Options) {
+		o.prefix = "/" + strings.Trim(prefix, "/")
+	}
+}
+
+func WithServer(url string) OptionFunc {
+	return func(o *Options) {
+		o.servers = append(o.servers, Server{URL: url})
+	}
+}
+
+const errorSchemaName = "Error"
+
+func Generate(api *grepo.API, opts ...OptionFunc) *Document {
+	options := &Options{
+		title:   "API",
+		version: "0.0.0",
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+	prefix := strings.TrimSuffix(options.prefix, "/")
+
+	g := schema.NewGenerator("#/components/schemas/")
+	errorRef := g.Define(errorSchemaName, &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"error":   {Type: "string"},
+			"code":    {Type: "string"},
+			"details": {Type: "object"},
+			"violations": {
+				Type: "array",
+				Items: &Schema{
+					Type: "object",
+					Properties: map[string]*Schema{
+						"path":       {Type: "string"},
+						"constraint": {Type: "string"},
+						"value":      {},
+						"message":    {Type: "string"},
+					},
+					Required: []string{"path", "constraint", "message"},
+				},
+			},
+		},
+		Required: []string{"error"},
+	})
+
+	doc := &Document{
+		OpenAPI: Version,
+		Info: Info{
+			Title:       options.title,
+			Description: api.Description(),
+			Version:     options.version,
+		},
+		Servers: options.servers,
+		Paths:   make(map[string]*PathItem),
+	}
+
+	for _, uc := range api.UseCases() {
+		doc.Paths[prefix+"/"+uc.Operation()] = &PathItem{
+			Post: operation(api, g, uc, errorRef),
+		}
+	}
+
+	doc.Components = &Components{Schemas: g.Defs()}
+
+	return doc
+}
+
+func operation(api *grepo.API, g *schema.Generator, uc grepo.Descriptor, errorRef *Schema) *Operation {
+	tags := make([]string, 0, len(uc.Groups()))
+	for _, group := range uc.Groups() {
+		tags = append(tags, group.Name())
+	}
+	meta := grepo.MetadataOf(uc)
+	for _, tag := range meta.Tags {
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+
+	errorResponse := &Response{
+		Description: "Error",
+		Content: map[string]*MediaType{
+			"application/json": {Schema: errorRef},
+		},
+	}
+
+	op := &Operation{
+		OperationID: uc.Operation(),
+		Summary:     uc.Description(),
+		Tags:        tags,
+		Deprecated:  meta.IsDeprecated(),
+		RequestBody: &RequestBody{
+			Required: true,
+			Content: map[string]*MediaType{
+				"application/json": {
+					Schema:   g.SchemaOf(refl.TypeOf(uc.Input())),
+					Examples: examples(meta.Examples, func(ex grepo.Example) any { return ex.Input }),
+				},
+			},
+		},
+		Responses: map[string]*Response{
+			"200": {
+				Description: "OK",
+				Content: map[string]*MediaType{
+					"application/json": {
+						Schema:   g.SchemaOf(refl.TypeOf(uc.Output())),
+						Examples: examples(meta.Examples, func(ex grepo.Example) any { return ex.Output }),
+					},
+				},
+			},
+			"400":     errorResponse,
+			"404":     errorResponse,
+			"default": errorResponse,
+		},
+	}
+	for _, code := range api.ErrorCodes(uc) {
+		status := strconv.Itoa(grepohttp.StatusOfCode(code))
+		if res, ok := op.Responses[status]; ok && res != errorResponse {
+			res.Description += ", " + string(code)
+			continue
+		}
+		op.Responses[status] = &Response{
+			Description: string(code),
+			Content:     errorResponse.Content,
+		}
+	}
+	return op
+}
+
+// examples keys the use case examples by name, numbering unnamed ones.
+func examples(exs []grepo.Example, value func(grepo.Example) any) map[string]*Example {
+	if len(exs) == 0 {
+		return nil
+	}
+	m := make(map[string]*Example, len(exs))
+	for i, ex := range exs {
+		key := ex.Name
+		if _, ok := m[key]; key == "" || ok {
+			key = "example" + strconv.Itoa(i+1)
+		}
+		m[key] = &Example{Summary: ex.Name, Value: value(ex)}
+	}
+	return m
+}
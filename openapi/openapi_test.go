@@ -0,0 +1,130 @@
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/ralsnet/grepo"
+)
+
+type testUser struct {
+	ID        string
+	Role      string `grepo:"enum:admin,user"`
+	Age       int    `grepo:"optional:true;min:0;max:150"`
+	CreatedAt time.Time
+}
+
+type testGetInput struct {
+	ID string
+}
+
+type testGetOutput struct {
+	User *testUser
+}
+
+type testFindOutput struct {
+	Users []*testUser `grepo:"optional:true"`
+}
+
+type testPage[T any] struct {
+	Items []T
+}
+
+type getUseCase struct{}
+
+func (u *getUseCase) Execute(ctx context.Context, input testGetInput) (*testGetOutput, error) {
+	return &testGetOutput{}, nil
+}
+
+type findUseCase struct{}
+
+func (u *findUseCase) Execute(ctx context.Context, input testGetInput) (*testFindOutput, error) {
+	return &testFindOutput{}, nil
+}
+
+type pageUseCase struct{}
+
+func (u *pageUseCase) Execute(ctx context.Context, input testGetInput) (*testPage[testUser], error) {
+	return &testPage[testUser]{}, nil
+}
+
+func TestGenerate(t *testing.T) {
+	group := grepo.NewGroup("users")
+	api := grepo.NewAPIBuilder().
+		WithDescription("Test API").
+		AddUseCase(grepo.NewUseCaseBuilder(&getUseCase{}).WithOperation("GetUser").WithGroup(group).WithErrorCodes(grepo.CodeNotFound, grepo.CodeConflict).Build()).
+		AddUseCase(grepo.NewUseCaseBuilder(&findUseCase{}).
+			WithOperation("FindUsers").
+			WithTags("search").
+			WithDeprecated("use SearchUsers").
+			AddExample("by id", testGetInput{ID: "u1"}, testFindOutput{}).
+			Build()).
+		AddUseCase(grepo.NewUseCaseBuilder(&pageUseCase{}).WithOperation("PageUsers").Build()).
+		Build()
+
+	doc := Generate(api, WithTitle("Test"), WithVersion("1.0.0"), WithPrefix("/api/"))
+
+	if doc.OpenAPI != Version {
+		t.Errorf("OpenAPI = %v, want %v", doc.OpenAPI, Version)
+	}
+	if doc.Info.Title != "Test" || doc.Info.Version != "1.0.0" || doc.Info.Description != "Test API" {
+		t.Errorf("Info = %+v", doc.Info)
+	}
+
+	op := doc.Paths["/api/GetUser"]
+	if op == nil || op.Post == nil {
+		t.Fatalf("Paths[/api/GetUser] is missing: %v", doc.Paths)
+	}
+	if len(op.Post.Tags) != 1 || op.Post.Tags[0] != "users" {
+		t.Errorf("Tags = %v, want [users]", op.Post.Tags)
+	}
+	if got := op.Post.RequestBody.Content["application/json"].Schema.Ref; got != "#/components/schemas/openapi.testGetInput" {
+		t.Errorf("RequestBody ref = %v", got)
+	}
+
+	find := doc.Paths["/api/FindUsers"].Post
+
+	user, ok := doc.Components.Schemas["openapi.testUser"]
+	if !ok {
+		t.Fatalf("Components.Schemas[openapi.testUser] is missing")
+	}
+
+	tests := []struct {
+		name string
+		got  any
+		want string
+	}{
+		{name: "required", got: user.Required, want: `["ID","Role","CreatedAt"]`},
+		{name: "enum", got: user.Properties["Role"].Enum, want: `["admin","user"]`},
+		{name: "min", got: user.Properties["Age"].Minimum, want: `0`},
+		{name: "max", got: user.Properties["Age"].Maximum, want: `150`},
+		{name: "time", got: user.Properties["CreatedAt"], want: `{"type":"string","format":"date-time"}`},
+		{name: "ref", got: doc.Components.Schemas["openapi.testGetOutput"].Properties["User"], want: `{"anyOf":[{"$ref":"#/components/schemas/openapi.testUser"},{"type":"null"}]}`},
+		{name: "declared code", got: op.Post.Responses["409"].Description, want: `"Conflict"`},
+		{name: "declared code on default status", got: op.Post.Responses["404"].Description, want: `"NotFound"`},
+		{name: "metadata tags", got: find.Tags, want: `["search"]`},
+		{name: "deprecated", got: find.Deprecated, want: `true`},
+		{name: "input example", got: find.RequestBody.Content["application/json"].Examples, want: `{"by id":{"summary":"by id","value":{"ID":"u1"}}}`},
+		{name: "output example", got: find.Responses["200"].Content["application/json"].Examples, want: `{"by id":{"summary":"by id","value":{"Users":null}}}`},
+		{name: "generic ref", got: doc.Paths["/api/PageUsers"].Post.Responses["200"].Content["application/json"].Schema, want: `{"$ref":"#/components/schemas/openapi.testPage_github.com.ralsnet.grepo.openapi.testUser"}`},
+		{name: "items", got: doc.Components.Schemas["openapi.testFindOutput"].Properties["Users"], want: `{"type":"array","items":{"anyOf":[{"$ref":"#/components/schemas/openapi.testUser"},{"type":"null"}]}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, _ := json.Marshal(tt.got)
+			if string(b) != tt.want {
+				t.Errorf("%s = %s, want %s", tt.name, b, tt.want)
+			}
+		})
+	}
+
+	valid := regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
+	for name := range doc.Components.Schemas {
+		if !valid.MatchString(name) {
+			t.Errorf("Components.Schemas has invalid name %q", name)
+		}
+	}
+}
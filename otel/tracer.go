@@ -0,0 +1,110 @@
+package otel
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ralsnet/grepo/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// Tracer adapts an OpenTelemetry tracer to tracing.Tracer.
+type Tracer struct {
+	tracer oteltrace.Tracer
+}
+
+func NewTracer(tracer oteltrace.Tracer) *Tracer {
+	return &Tracer{tracer: tracer}
+}
+
+func (t *Tracer) Start(ctx context.Context, name string, attrs ...tracing.Attribute) (context.Context, tracing.Span) {
+	// A traceparent received by a grepo transport only lives in the tracing
+	// package's context values until OpenTelemetry learns about it here.
+	if !oteltrace.SpanContextFromContext(ctx).IsValid() {
+		if sc := tracing.SpanContextFromContext(ctx); sc.IsValid() {
+			ctx = oteltrace.ContextWithRemoteSpanContext(ctx, toOTel(sc))
+		}
+	}
+	ctx, span := t.tracer.Start(ctx, name, oteltrace.WithAttributes(attributes(attrs)...))
+	return ctx, &spanAdapter{span: span}
+}
+
+type spanAdapter struct {
+	span oteltrace.Span
+}
+
+func (s *spanAdapter) SpanContext() tracing.SpanContext {
+	return fromOTel(s.span.SpanContext())
+}
+
+func (s *spanAdapter) SetAttributes(attrs ...tracing.Attribute) {
+	s.span.SetAttributes(attributes(attrs)...)
+}
+
+func (s *spanAdapter) RecordError(err error) {
+	s.span.RecordError(err)
+}
+
+func (s *spanAdapter) SetStatus(code tracing.StatusCode, description string) {
+	switch code {
+	case tracing.StatusOK:
+		s.span.SetStatus(codes.Ok, description)
+	case tracing.StatusError:
+		s.span.SetStatus(codes.Error, description)
+	default:
+		s.span.SetStatus(codes.Unset, description)
+	}
+}
+
+func (s *spanAdapter) End() {
+	s.span.End()
+}
+
+func toOTel(sc tracing.SpanContext) oteltrace.SpanContext {
+	var flags oteltrace.TraceFlags
+	if sc.Sampled {
+		flags = oteltrace.FlagsSampled
+	}
+	return oteltrace.NewSpanContext(oteltrace.SpanContextConfig{
+		TraceID:    oteltrace.TraceID(sc.TraceID),
+		SpanID:     oteltrace.SpanID(sc.SpanID),
+		TraceFlags: flags,
+		Remote:     sc.Remote,
+	})
+}
+
+func fromOTel(sc oteltrace.SpanContext) tracing.SpanContext {
+	return tracing.SpanContext{
+		TraceID: tracing.TraceID(sc.TraceID()),
+		SpanID:  tracing.SpanID(sc.SpanID()),
+		Sampled: sc.IsSampled(),
+		Remote:  sc.IsRemote(),
+	}
+}
+
+func attributes(attrs []tracing.Attribute) []attribute.KeyValue {
+	kvs := make([]attribute.KeyValue, 0, len(attrs))
+	for _, attr := range attrs {
+		switch v := attr.Value.(type) {
+		case string:
+			kvs = append(kvs, attribute.String(attr.Key, v))
+		case bool:
+			kvs = append(kvs, attribute.Bool(attr.Key, v))
+		case int:
+			kvs = append(kvs, attribute.Int(attr.Key, v))
+		case int64:
+			kvs = append(kvs, attribute.Int64(attr.Key, v))
+		case float64:
+			kvs = append(kvs, attribute.Float64(attr.Key, v))
+		case []string:
+			kvs = append(kvs, attribute.StringSlice(attr.Key, v))
+		case fmt.Stringer:
+			kvs = append(kvs, attribute.String(attr.Key, v.String()))
+		default:
+			kvs = append(kvs, attribute.String(attr.Key, fmt.Sprint(v)))
+		}
+	}
+	return kvs
+}
@@ -0,0 +1,54 @@
+package otel
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ralsnet/grepo/tracing"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracer(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tracer := NewTracer(provider.Tracer("grepo"))
+
+	remote, _ := tracing.ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := tracing.ContextWithRemoteSpanContext(context.Background(), remote)
+
+	ctx, outer := tracer.Start(ctx, "outer", tracing.Attr("grepo.operation", "outer"), tracing.Attr("grepo.groups", []string{"users"}))
+	_, inner := tracer.Start(ctx, "inner", tracing.Attr("grepo.attempts", 2))
+	inner.RecordError(errors.New("boom"))
+	inner.SetStatus(tracing.StatusError, "boom")
+	inner.End()
+	outer.SetStatus(tracing.StatusOK, "")
+	outer.End()
+
+	if sc := outer.SpanContext(); sc.TraceID != remote.TraceID || !sc.Sampled || sc.Remote {
+		t.Errorf("outer.SpanContext() = %+v", sc)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("spans = %d, want 2", len(spans))
+	}
+	gotInner, gotOuter := spans[0], spans[1]
+	if gotOuter.Parent().SpanID() != [8]byte(remote.SpanID) || !gotOuter.Parent().IsRemote() {
+		t.Errorf("outer parent = %v", gotOuter.Parent())
+	}
+	if gotInner.Parent().SpanID() != gotOuter.SpanContext().SpanID() {
+		t.Errorf("inner parent = %v", gotInner.Parent())
+	}
+	if gotOuter.Status().Code != codes.Ok || gotInner.Status().Code != codes.Error || len(gotInner.Events()) != 1 {
+		t.Errorf("status = %v, %v", gotOuter.Status(), gotInner.Status())
+	}
+	if attrs := gotOuter.Attributes(); len(attrs) != 2 || attrs[1].Value.AsStringSlice()[0] != "users" {
+		t.Errorf("outer attributes = %v", attrs)
+	}
+	if attrs := gotInner.Attributes(); len(attrs) != 1 || attrs[0].Value.AsInt64() != 2 {
+		t.Errorf("inner attributes = %v", attrs)
+	}
+}
@@ -0,0 +1,128 @@
+package grepo
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/ralsnet/grepo/refl"
+)
+
+// interactor lets the API drive a use case through its typed hook chains.
+// *Interactor implements it; other Descriptor implementations are adapted by
+// reflectInteractor.
+type interactor interface {
+	beforeAny(ctx context.Context, input any) (context.Context, any, error)
+	executeAny(ctx context.Context, input any) (any, error)
+	aroundAny(ctx context.Context, input any, next Next[any, any]) (any, error)
+	afterAny(ctx context.Context, input any, output any)
+	errorAny(ctx context.Context, input any, err error)
+}
+
+// plan holds everything executeUseCase needs for a descriptor so that it is
+// computed once at build time instead of on every call.
+type plan struct {
+	desc       Descriptor
+	interactor interactor
+	groups     []*Group
+	input      *refl.Type
+	output     *refl.Type
+	// defaults holds the input types that declare default values.
+	defaults map[*refl.Type]bool
+}
+
+func (a *API) newPlan(d Descriptor) *plan {
+	it, ok := d.(interactor)
+	if !ok {
+		it = newReflectInteractor(d)
+	}
+	input := refl.TypeOf(d.Input())
+	return &plan{
+		desc:       d,
+		interactor: it,
+		groups:     append([]*Group{a.root}, d.Groups()...),
+		input:      input,
+		output:     refl.TypeOf(d.Output()),
+		defaults:   defaultTypes(input),
+	}
+}
+
+func (a *API) planOf(d Descriptor) *plan {
+	if p, ok := a.plans[d.Operation()]; ok && p.desc == d {
+		return p
+	}
+	return a.newPlan(d)
+}
+
+type reflectInteractor struct {
+	execute reflect.Value
+	before  reflect.Value
+	after   reflect.Value
+	error   reflect.Value
+}
+
+func newReflectInteractor(d Descriptor) *reflectInteractor {
+	v := reflect.ValueOf(d)
+	return &reflectInteractor{
+		execute: v.MethodByName("Execute"),
+		before:  v.MethodByName("DoBeforeHook"),
+		after:   v.MethodByName("DoAfterHook"),
+		error:   v.MethodByName("DoErrorHook"),
+	}
+}
+
+func (r *reflectInteractor) beforeAny(ctx context.Context, input any) (context.Context, any, error) {
+	if !r.before.IsValid() {
+		return ctx, input, ErrNotFound
+	}
+
+	// Create a pointer to input value
+	ptr := reflect.New(reflect.ValueOf(input).Type())
+	ptr.Elem().Set(reflect.ValueOf(input))
+
+	o := r.before.Call([]reflect.Value{reflect.ValueOf(ctx), ptr})
+	if len(o) != 2 {
+		return ctx, input, ErrInvalid
+	}
+	input = ptr.Elem().Interface()
+	c, ok := o[0].Interface().(context.Context)
+	if !ok {
+		return ctx, input, ErrInvalid
+	}
+	err, _ := o[1].Interface().(error)
+	return c, input, err
+}
+
+func (r *reflectInteractor) executeAny(ctx context.Context, input any) (any, error) {
+	if !r.execute.IsValid() {
+		return nil, ErrNotFound
+	}
+	o := r.execute.Call([]reflect.Value{reflect.ValueOf(ctx), reflect.ValueOf(input)})
+	if len(o) != 2 {
+		return nil, ErrInvalid
+	}
+	err, _ := o[1].Interface().(error)
+	if err != nil {
+		return nil, err
+	}
+	return o[0].Interface(), nil
+}
+
+// aroundAny runs next directly: the typed middleware of a custom Descriptor
+// cannot be reached through reflection.
+func (r *reflectInteractor) aroundAny(ctx context.Context, input any, next Next[any, any]) (any, error) {
+	return next(ctx, input)
+}
+
+func (r *reflectInteractor) afterAny(ctx context.Context, input any, output any) {
+	if !r.after.IsValid() {
+		return
+	}
+	r.after.Call([]reflect.Value{reflect.ValueOf(ctx), reflect.ValueOf(input), reflect.ValueOf(output)})
+}
+
+func (r *reflectInteractor) errorAny(ctx context.Context, input any, err error) {
+	if !r.error.IsValid() {
+		return
+	}
+	r.error.Call([]reflect.Value{reflect.ValueOf(ctx), reflect.ValueOf(input), reflect.ValueOf(err)})
+}
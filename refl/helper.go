@@ -2,6 +2,7 @@ package refl
 
 import (
 	"reflect"
+	"slices"
 	"strings"
 )
 
@@ -11,6 +12,12 @@ func nameOf(t reflect.Type) string {
 	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
 		return "[]" + nameOf(t.Elem())
 	}
+	if t.Kind() == reflect.Map {
+		return "map[" + nameOf(t.Key()) + "]" + nameOf(t.Elem())
+	}
+	if t.Name() == "" && kindOf(t) == KindUnknown {
+		return t.String()
+	}
 	name := t.Name()
 	pkg := t.PkgPath()
 	parts := strings.Split(pkg, "/")
@@ -30,3 +37,117 @@ func stripPointer(rt reflect.Type) reflect.Type {
 	}
 	return rt
 }
+
+type structField struct {
+	field     reflect.StructField
+	name      string
+	index     []int
+	tagged    bool
+	omitEmpty bool
+}
+
+// structFields lists the fields of a struct type as encoding/json sees them:
+// fields tagged json:"-" are skipped and fields of embedded structs are
+// promoted, with conflicting names resolved by depth and then by tag.
+func structFields(rt reflect.Type) []structField {
+	fields := collectFields(rt, nil, map[reflect.Type]bool{rt: true})
+
+	byName := make(map[string][]structField)
+	for _, f := range fields {
+		byName[f.name] = append(byName[f.name], f)
+	}
+
+	result := make([]structField, 0, len(fields))
+	for _, f := range fields {
+		if dominant, ok := dominantField(byName[f.name]); ok && slices.Equal(dominant.index, f.index) {
+			result = append(result, f)
+		}
+	}
+	return result
+}
+
+func collectFields(rt reflect.Type, index []int, visited map[reflect.Type]bool) []structField {
+	fields := make([]structField, 0, rt.NumField())
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		fieldIndex := make([]int, len(index)+1)
+		copy(fieldIndex, index)
+		fieldIndex[len(index)] = i
+
+		if sf.Anonymous {
+			et := stripPointer(sf.Type)
+			if !sf.IsExported() && et.Kind() != reflect.Struct {
+				continue
+			}
+			if name == "" && et.Kind() == reflect.Struct {
+				if visited[et] {
+					continue
+				}
+				visited[et] = true
+				fields = append(fields, collectFields(et, fieldIndex, visited)...)
+				delete(visited, et)
+				continue
+			}
+		} else if !sf.IsExported() {
+			continue
+		}
+
+		f := structField{
+			field:     sf,
+			name:      name,
+			index:     fieldIndex,
+			tagged:    name != "",
+			omitEmpty: hasOption(opts, "omitempty"),
+		}
+		if f.name == "" {
+			f.name = sf.Name
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+func dominantField(fields []structField) (structField, bool) {
+	if len(fields) == 1 {
+		return fields[0], true
+	}
+	depth := len(fields[0].index)
+	for _, f := range fields[1:] {
+		depth = min(depth, len(f.index))
+	}
+	var dominant []structField
+	var tagged []structField
+	for _, f := range fields {
+		if len(f.index) != depth {
+			continue
+		}
+		dominant = append(dominant, f)
+		if f.tagged {
+			tagged = append(tagged, f)
+		}
+	}
+	if len(dominant) == 1 {
+		return dominant[0], true
+	}
+	if len(tagged) == 1 {
+		return tagged[0], true
+	}
+	return structField{}, false
+}
+
+func hasOption(opts string, option string) bool {
+	for opts != "" {
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
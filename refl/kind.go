@@ -5,6 +5,7 @@ import "reflect"
 const (
 	KindObject  = "object"
 	KindArray   = "array"
+	KindMap     = "map"
 	KindString  = "string"
 	KindInt     = "int"
 	KindInt8    = "int8"
@@ -20,6 +21,7 @@ const (
 	KindFloat64 = "float64"
 	KindBool    = "bool"
 	KindTime    = "time"
+	KindUnknown = "unknown"
 )
 
 func kindOf(t reflect.Type) string {
@@ -32,6 +34,8 @@ func kindOf(t reflect.Type) string {
 		return KindObject
 	case reflect.Slice, reflect.Array:
 		return KindArray
+	case reflect.Map:
+		return KindMap
 	case reflect.String:
 		return KindString
 	case reflect.Int:
@@ -61,6 +65,6 @@ func kindOf(t reflect.Type) string {
 	case reflect.Bool:
 		return KindBool
 	default:
-		return "unknown"
+		return KindUnknown
 	}
 }
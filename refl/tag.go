@@ -0,0 +1,167 @@
+package refl
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	FormatEmail = "email"
+	FormatUUID  = "uuid"
+	FormatURI   = "uri"
+	FormatDate  = "date"
+)
+
+func parseTag(f *Field, tag string) error {
+	var errs []error
+	for _, part := range strings.Split(tag, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, ":")
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		if !ok {
+			switch key {
+			case "optional":
+				f.Optional = true
+			case "sensitive":
+				f.Sensitive = true
+			default:
+				errs = append(errs, fmt.Errorf("grepo tag %q: missing value", part))
+			}
+			continue
+		}
+		if err := parseTagEntry(f, key, value); err != nil {
+			errs = append(errs, fmt.Errorf("grepo tag %q: %w", part, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func parseTagEntry(f *Field, key string, value string) error {
+	switch key {
+	case "optional", "sensitive":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		if key == "optional" {
+			f.Optional = b
+		} else {
+			f.Sensitive = b
+		}
+	case "enum":
+		f.Enum = splitList(value)
+	case "custom":
+		f.Custom = splitList(value)
+	case "min", "max":
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		if key == "min" {
+			f.Min = &n
+		} else {
+			f.Max = &n
+		}
+	case "minLen", "maxLen", "minItems", "maxItems":
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		if n < 0 {
+			return fmt.Errorf("must not be negative")
+		}
+		switch key {
+		case "minLen":
+			f.MinLen = &n
+		case "maxLen":
+			f.MaxLen = &n
+		case "minItems":
+			f.MinItems = &n
+		case "maxItems":
+			f.MaxItems = &n
+		}
+	case "pattern":
+		re, err := regexp.Compile(value)
+		if err != nil {
+			return err
+		}
+		f.Pattern = value
+		f.regexp = re
+	case "desc":
+		f.Description = value
+	case "example":
+		f.Example = value
+	case "default":
+		f.Default = value
+	case "format":
+		switch value {
+		case FormatEmail, FormatUUID, FormatURI, FormatDate:
+			f.Format = value
+		default:
+			return fmt.Errorf("unknown format %q", value)
+		}
+	default:
+		return fmt.Errorf("unknown key %q", key)
+	}
+	return nil
+}
+
+func splitList(value string) []string {
+	values := strings.Split(value, ",")
+	for i := range values {
+		values[i] = strings.TrimSpace(values[i])
+	}
+	return values
+}
+
+// checkValues reports example and default entries that do not parse as a
+// value of the field's type.
+func checkValues(f *Field) error {
+	var errs []error
+	for _, entry := range [][2]string{{"example", f.Example}, {"default", f.Default}} {
+		if entry[1] == "" {
+			continue
+		}
+		if _, err := parseValue(f.rtype, entry[1]); err != nil {
+			errs = append(errs, fmt.Errorf("grepo tag %q: %w", entry[0]+":"+entry[1], err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+var textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+
+// parseValue parses s as a value of type rt. Strings and types implementing
+// encoding.TextUnmarshaler, such as time.Time, are taken verbatim; anything
+// else is read as JSON, e.g. 3, true, ["a","b"] or {"Name":"x"}.
+func parseValue(rt reflect.Type, s string) (reflect.Value, error) {
+	if rt.Kind() == reflect.Pointer {
+		v, err := parseValue(rt.Elem(), s)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		p := reflect.New(rt.Elem())
+		p.Elem().Set(v)
+		return p, nil
+	}
+
+	data := []byte(s)
+	if rt.Kind() == reflect.String || reflect.PointerTo(rt).Implements(textUnmarshalerType) {
+		data, _ = json.Marshal(s)
+	}
+	p := reflect.New(rt)
+	if err := json.Unmarshal(data, p.Interface()); err != nil {
+		return reflect.Value{}, err
+	}
+	return p.Elem(), nil
+}
@@ -1,32 +1,96 @@
 package refl
 
 import (
-	"fmt"
+	"errors"
 	"reflect"
-	"strings"
+	"regexp"
 )
 
 type Field struct {
-	Field    string
-	Type     *Type
-	Optional bool     `json:",omitempty"`
-	Enum     []string `json:",omitempty"`
-	Custom   []string `json:",omitempty"`
-	Min      *int     `json:",omitempty"`
-	Max      *int     `json:",omitempty"`
-	parent   *Type
+	// Field is the Go name of the field and Name its name on the wire, taken
+	// from the json tag when there is one.
+	Field     string
+	Name      string
+	Index     []int `json:"-"`
+	Type      *Type
+	OmitEmpty bool     `json:",omitempty"`
+	Optional  bool     `json:",omitempty"`
+	Enum      []string `json:",omitempty"`
+	Custom    []string `json:",omitempty"`
+	Min       *float64 `json:",omitempty"`
+	Max       *float64 `json:",omitempty"`
+	MinLen    *int     `json:",omitempty"`
+	MaxLen    *int     `json:",omitempty"`
+	MinItems  *int     `json:",omitempty"`
+	MaxItems  *int     `json:",omitempty"`
+	Pattern   string   `json:",omitempty"`
+	Format    string   `json:",omitempty"`
+	Sensitive bool     `json:",omitempty"`
+	// Description, Example and Default come from the desc, example and
+	// default tag entries. Description falls back to the doc struct tag,
+	// which may contain semicolons.
+	Description string `json:",omitempty"`
+	Example     string `json:",omitempty"`
+	Default     string `json:",omitempty"`
+	parent      *Type
+	rtype       reflect.Type
+	regexp      *regexp.Regexp
+	err         error
 }
 
 func (f *Field) Parent() *Type {
 	return f.parent
 }
 
+func (f *Field) Regexp() *regexp.Regexp {
+	return f.regexp
+}
+
+// DefaultValue returns a new value of the field's type parsed from Default.
+// Each call allocates, so the value may be stored without aliasing others.
+func (f *Field) DefaultValue() (reflect.Value, bool) {
+	return f.value(f.Default)
+}
+
+// ExampleValue returns a value of the field's type parsed from Example.
+func (f *Field) ExampleValue() (reflect.Value, bool) {
+	return f.value(f.Example)
+}
+
+func (f *Field) value(s string) (reflect.Value, bool) {
+	if s == "" || f.rtype == nil {
+		return reflect.Value{}, false
+	}
+	v, err := parseValue(f.rtype, s)
+	return v, err == nil
+}
+
+// Err reports malformed entries found in the field's grepo tag.
+func (f *Field) Err() error {
+	return f.err
+}
+
 type Type struct {
 	Kind    string
 	Pkg     string `json:",omitempty"`
 	Name    string
+	Pointer bool     `json:",omitempty"`
 	Fields  []*Field `json:",omitempty"`
+	Key     *Type    `json:",omitempty"`
 	Element *Type    `json:",omitempty"`
+	// Ref marks a struct type that occurs within itself. It stands for the
+	// enclosing type of the same name and has no fields of its own; Resolve
+	// returns the type that has them.
+	Ref bool `json:",omitempty"`
+	ref *Type
+}
+
+// Resolve returns the type a Ref refers to, or t itself.
+func (t *Type) Resolve() *Type {
+	if t.ref != nil {
+		return t.ref
+	}
+	return t
 }
 
 func TypeOf(v any) *Type {
@@ -34,72 +98,78 @@ func TypeOf(v any) *Type {
 }
 
 func TypeFor(t reflect.Type) *Type {
+	return typeFor(t, make(map[reflect.Type]*Type))
+}
+
+// typeFor builds the type of t. building holds the struct types whose fields
+// are being built, so that a recursive type ends in a Ref instead of
+// recursing forever.
+func typeFor(t reflect.Type, building map[reflect.Type]*Type) *Type {
 	rt := stripPointer(t)
 	s := &Type{
-		Kind: kindOf(rt),
-		Pkg:  rt.PkgPath(),
-		Name: nameOf(t),
+		Kind:    kindOf(rt),
+		Pkg:     rt.PkgPath(),
+		Name:    nameOf(t),
+		Pointer: t.Kind() == reflect.Pointer,
 	}
 
 	switch s.Kind {
 	case KindObject:
+		if ref, ok := building[rt]; ok {
+			s.Ref = true
+			s.ref = ref
+			return s
+		}
+		building[rt] = s
+		defer delete(building, rt)
+
 		s.Fields = make([]*Field, 0)
-		numField := rt.NumField()
-		for i := 0; i < numField; i++ {
-			ft := rt.Field(i)
-			if !ft.IsExported() {
-				continue
-			}
+		for _, sf := range structFields(rt) {
+			ft := sf.field
 
 			f := &Field{
-				Field:  ft.Name,
-				Type:   TypeFor(ft.Type),
-				parent: s,
+				Field:       ft.Name,
+				Name:        sf.name,
+				Index:       sf.index,
+				Type:        typeFor(ft.Type, building),
+				OmitEmpty:   sf.omitEmpty,
+				Description: ft.Tag.Get("doc"),
+				parent:      s,
+				rtype:       ft.Type,
 			}
 
-			tag := ft.Tag.Get("grepo")
-			parts := strings.Split(tag, ";")
-			for _, part := range parts {
-				part = strings.TrimSpace(part)
-				kv := strings.SplitN(part, ":", 2)
-				if len(kv) != 2 {
-					continue
-				}
-				switch kv[0] {
-				case "optional":
-					f.Optional = kv[1] == "true"
-				case "enum":
-					enumValues := strings.Split(kv[1], ",")
-					for i := range enumValues {
-						enumValues[i] = strings.TrimSpace(enumValues[i])
-					}
-					f.Enum = enumValues
-				case "min", "max":
-					value := strings.TrimSpace(kv[1])
-					if value != "" {
-						var mv int
-						fmt.Sscanf(value, "%d", &mv)
-						if kv[0] == "max" {
-							f.Max = &mv
-							continue
-						}
-						f.Min = &mv
-					}
-
-				case "custom":
-					customValues := strings.Split(kv[1], ",")
-					for i := range customValues {
-						customValues[i] = strings.TrimSpace(customValues[i])
-					}
-					f.Custom = customValues
-				}
-			}
+			f.err = errors.Join(parseTag(f, ft.Tag.Get("grepo")), checkValues(f))
 
 			s.Fields = append(s.Fields, f)
 		}
 	case KindArray:
-		elemType := TypeFor(rt.Elem())
-		s.Element = elemType
+		s.Element = typeFor(rt.Elem(), building)
+	case KindMap:
+		s.Key = typeFor(rt.Key(), building)
+		s.Element = typeFor(rt.Elem(), building)
 	}
 	return s
 }
+
+// Walk calls fn for every field reachable from t, depth first. Array and map
+// elements are denoted by "[]" in the path. A Ref is not walked into, so the
+// fields of a recursive type are visited once.
+func Walk(t *Type, fn func(path string, f *Field)) {
+	walk(t, "", fn)
+}
+
+func walk(t *Type, path string, fn func(path string, f *Field)) {
+	switch t.Kind {
+	case KindObject:
+		for _, f := range t.Fields {
+			fp := f.Name
+			if path != "" {
+				fp = path + "." + f.Name
+			}
+			fn(fp, f)
+			walk(f.Type, fp, fn)
+		}
+	case KindArray, KindMap:
+		walk(t.Element, path+"[]", fn)
+	}
+}
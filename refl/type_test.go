@@ -0,0 +1,275 @@
+package refl
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testBase struct {
+	ID      string `json:"id"`
+	Version int    `json:"version,omitempty"`
+}
+
+type testAudit struct {
+	CreatedBy string
+}
+
+type testEmbedded struct {
+	testBase
+	*testAudit
+	Name     string    `json:"name" grepo:"optional:true"`
+	Password string    `json:"-"`
+	Version  string    `json:"version"`
+	Meta     testAudit `json:"meta"`
+	internal string
+}
+
+func TestTypeFor_Fields(t *testing.T) {
+	type want struct {
+		field     string
+		name      string
+		index     []int
+		omitEmpty bool
+		optional  bool
+	}
+	tests := []struct {
+		name string
+		v    any
+		want []want
+	}{
+		{
+			name: "正常系: jsonタグ名を使用する",
+			v: struct {
+				ID   string `json:"id"`
+				Name string
+			}{},
+			want: []want{
+				{field: "ID", name: "id", index: []int{0}},
+				{field: "Name", name: "Name", index: []int{1}},
+			},
+		},
+		{
+			name: "正常系: 埋め込み構造体を展開し、スキップと衝突を解決する",
+			v:    testEmbedded{},
+			want: []want{
+				{field: "ID", name: "id", index: []int{0, 0}},
+				{field: "CreatedBy", name: "CreatedBy", index: []int{1, 0}},
+				{field: "Name", name: "name", index: []int{2}, optional: true},
+				{field: "Version", name: "version", index: []int{4}},
+				{field: "Meta", name: "meta", index: []int{5}},
+			},
+		},
+		{
+			name: "正常系: omitemptyを記録する",
+			v:    testBase{},
+			want: []want{
+				{field: "ID", name: "id", index: []int{0}},
+				{field: "Version", name: "version", index: []int{1}, omitEmpty: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TypeOf(tt.v)
+			if len(got.Fields) != len(tt.want) {
+				t.Fatalf("len(Fields) = %v, want %v", len(got.Fields), len(tt.want))
+			}
+			for i, w := range tt.want {
+				f := got.Fields[i]
+				if f.Field != w.field || f.Name != w.name || !reflect.DeepEqual(f.Index, w.index) ||
+					f.OmitEmpty != w.omitEmpty || f.Optional != w.optional {
+					t.Errorf("Fields[%d] = {%s %s %v %v %v}, want %+v", i, f.Field, f.Name, f.Index, f.OmitEmpty, f.Optional, w)
+				}
+			}
+		})
+	}
+}
+
+func TestType_MarshalJSON(t *testing.T) {
+	b, err := json.Marshal(TypeOf(testBase{}).Fields[0])
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	want := `{"Field":"ID","Name":"id","Type":{"Kind":"string","Name":"string"}}`
+	if string(b) != want {
+		t.Errorf("Marshal() = %s, want %s", b, want)
+	}
+}
+
+func TestTypeFor_Map(t *testing.T) {
+	got := TypeOf(map[string][]*testBase{})
+	if got.Kind != KindMap {
+		t.Fatalf("Kind = %v, want %v", got.Kind, KindMap)
+	}
+	if got.Name != "map[string][]*refl.testBase" {
+		t.Errorf("Name = %v", got.Name)
+	}
+	if got.Key.Kind != KindString {
+		t.Errorf("Key.Kind = %v, want %v", got.Key.Kind, KindString)
+	}
+	if got.Element.Kind != KindArray || got.Element.Element.Kind != KindObject || !got.Element.Element.Pointer {
+		t.Errorf("Element = %+v", got.Element)
+	}
+}
+
+type testNode struct {
+	Name     string      `json:"name"`
+	Children []*testNode `json:"children"`
+	Parent   *testNode   `json:"parent"`
+}
+
+func TestTypeFor_Recursive(t *testing.T) {
+	got := TypeOf(testNode{})
+	if len(got.Fields) != 3 {
+		t.Fatalf("len(Fields) = %v, want 3", len(got.Fields))
+	}
+	children := got.Fields[1].Type.Element
+	if !children.Ref || !children.Pointer || children.Name != "*refl.testNode" || len(children.Fields) != 0 {
+		t.Errorf("Children element = %+v", children)
+	}
+	if children.Resolve() != got {
+		t.Errorf("Resolve() = %p, want %p", children.Resolve(), got)
+	}
+	if parent := got.Fields[2].Type; !parent.Ref || parent.Resolve() != got {
+		t.Errorf("Parent = %+v", parent)
+	}
+	if got.Resolve() != got {
+		t.Errorf("Resolve() of a non-Ref = %p, want %p", got.Resolve(), got)
+	}
+
+	var paths []string
+	Walk(got, func(path string, f *Field) {
+		paths = append(paths, path)
+	})
+	if want := []string{"name", "children", "parent"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("Walk() paths = %v, want %v", paths, want)
+	}
+
+	b, err := json.Marshal(got.Fields[2])
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	want := `{"Field":"Parent","Name":"parent","Type":{"Kind":"object","Pkg":"github.com/ralsnet/grepo/refl","Name":"*refl.testNode","Pointer":true,"Ref":true}}`
+	if string(b) != want {
+		t.Errorf("Marshal() = %s, want %s", b, want)
+	}
+}
+
+func TestTypeFor_Tag(t *testing.T) {
+	tests := []struct {
+		name    string
+		v       any
+		check   func(*Field) bool
+		wantErr bool
+	}{
+		{
+			name: "正常系: 値なしのoptional",
+			v: struct {
+				V string `grepo:"optional"`
+			}{},
+			check: func(f *Field) bool { return f.Optional },
+		},
+		{
+			name: "正常系: 値なしのsensitive",
+			v: struct {
+				V string `grepo:"sensitive;optional"`
+			}{},
+			check: func(f *Field) bool { return f.Sensitive && f.Optional },
+		},
+		{
+			name: "正常系: 小数のmin/max",
+			v: struct {
+				V float64 `grepo:"min:0.5;max:1.5"`
+			}{},
+			check: func(f *Field) bool { return *f.Min == 0.5 && *f.Max == 1.5 },
+		},
+		{
+			name: "正常系: 長さ・件数・パターン・フォーマット",
+			v: struct {
+				V []string `grepo:"minLen:1;maxLen:2;minItems:1;maxItems:3;pattern:^a$;format:uuid"`
+			}{},
+			check: func(f *Field) bool {
+				return *f.MinLen == 1 && *f.MaxLen == 2 && *f.MinItems == 1 && *f.MaxItems == 3 &&
+					f.Regexp().MatchString("a") && f.Format == FormatUUID
+			},
+		},
+		{
+			name: "正常系: 説明・例・デフォルト値",
+			v: struct {
+				V *int `grepo:"desc:Page size: 1 to 100;example:20;default:10"`
+			}{},
+			check: func(f *Field) bool {
+				d, ok := f.DefaultValue()
+				e, _ := f.ExampleValue()
+				d2, _ := f.DefaultValue()
+				return f.Description == "Page size: 1 to 100" && ok && *d.Interface().(*int) == 10 &&
+					*e.Interface().(*int) == 20 && d.Pointer() != d2.Pointer()
+			},
+		},
+		{
+			name: "正常系: docタグと時刻・配列のデフォルト値",
+			v: struct {
+				V []time.Time `doc:"Dates; newest first" grepo:"default:[\"2025-01-01T00:00:00Z\"]"`
+			}{},
+			check: func(f *Field) bool {
+				d, ok := f.DefaultValue()
+				return f.Description == "Dates; newest first" && ok &&
+					d.Interface().([]time.Time)[0].Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+			},
+		},
+		{
+			name: "正常系: 文字列と時刻はそのまま解釈",
+			v: struct {
+				V time.Time `grepo:"default:2025-01-01T00:00:00Z"`
+				S string    `grepo:"example:a b"`
+			}{},
+			check: func(f *Field) bool {
+				d, ok := f.DefaultValue()
+				return ok && d.Interface().(time.Time).Year() == 2025
+			},
+		},
+		{
+			name: "異常系: 型に合わないデフォルト値",
+			v: struct {
+				V int `grepo:"default:ten"`
+			}{},
+			wantErr: true,
+		},
+		{
+			name: "異常系: 数値でないmin",
+			v: struct {
+				V int `grepo:"min:abc"`
+			}{},
+			wantErr: true,
+		},
+		{
+			name: "異常系: 不正な正規表現",
+			v: struct {
+				V string `grepo:"pattern:("`
+			}{},
+			wantErr: true,
+		},
+		{
+			name: "異常系: 未知のフォーマットとキー",
+			v: struct {
+				V string `grepo:"format:ipv9;unknown:1"`
+			}{},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := TypeOf(tt.v).Fields[0]
+			if (f.Err() != nil) != tt.wantErr {
+				t.Fatalf("Err() = %v, wantErr %v", f.Err(), tt.wantErr)
+			}
+			if tt.check != nil && !tt.check(f) {
+				t.Errorf("unexpected field %+v", f)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package grepo
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// RetryPolicy retries the execution of idempotent use cases. Before hooks and
+// input validation run once; middleware and the use case run on every attempt.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	MaxAttempts int
+	// Backoff is the delay before the second attempt. Each further delay is
+	// Multiplier (2 when zero) times the previous one, capped at MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	Multiplier float64
+	// Jitter randomizes each delay by up to this fraction of it, from 0 to 1.
+	Jitter float64
+	// Rand returns the numbers in [0, 1) that drive the jitter. When nil,
+	// math/rand/v2 is used. Tests set it to get exact delays.
+	Rand func() float64
+	// Retryable reports whether err is worth another attempt. When nil, only
+	// errors matching ErrUnavailable are retried.
+	Retryable func(err error) bool
+}
+
+// RetryOn returns a Retryable predicate matching any of targets with errors.Is.
+func RetryOn(targets ...error) func(err error) bool {
+	return func(err error) bool {
+		for _, target := range targets {
+			if errors.Is(err, target) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func (p *RetryPolicy) shouldRetry(attempt int, err error) bool {
+	if p == nil || attempt >= p.MaxAttempts {
+		return false
+	}
+	if p.Retryable == nil {
+		return errors.Is(err, ErrUnavailable)
+	}
+	return p.Retryable(err)
+}
+
+func (p *RetryPolicy) random() float64 {
+	if p.Rand == nil {
+		return rand.Float64()
+	}
+	return p.Rand()
+}
+
+// delay returns the wait after the given failed attempt; r in [0, 1) drives the
+// jitter.
+func (p *RetryPolicy) delay(attempt int, r float64) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier == 0 {
+		multiplier = 2
+	}
+	d := float64(p.Backoff) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 && d > float64(p.MaxBackoff) {
+		d = float64(p.MaxBackoff)
+	}
+	d += d * p.Jitter * (2*r - 1)
+	return time.Duration(max(d, 0))
+}
+
+// RetryPolicy returns the policy applied to d: its own, else that of its first
+// group defining one. Operations that are not idempotent are never retried.
+func (a *API) RetryPolicy(d Descriptor) *RetryPolicy {
+	if !isIdempotent(d) {
+		return nil
+	}
+	if r, ok := d.(interface{ RetryPolicy() *RetryPolicy }); ok && r.RetryPolicy() != nil {
+		return r.RetryPolicy()
+	}
+	for _, g := range d.Groups() {
+		if g.retry != nil {
+			return g.retry
+		}
+	}
+	return nil
+}
+
+func isIdempotent(d Descriptor) bool {
+	i, ok := d.(interface{ Idempotent() bool })
+	return ok && i.Idempotent()
+}
+
+// retry runs fn until it succeeds or the policy gives up, exposing the attempt
+// number through the context it passes and returns.
+func (a *API) retry(ctx context.Context, policy *RetryPolicy, fn func(ctx context.Context) (any, error)) (context.Context, any, error) {
+	base := ctx
+	for attempt := 1; ; attempt++ {
+		ctx = WithAttempt(base, attempt)
+		output, err := fn(ctx)
+		if err == nil || !policy.shouldRetry(attempt, err) {
+			return ctx, output, err
+		}
+		if werr := a.sleep(ctx, policy.delay(attempt, policy.random())); werr != nil {
+			return ctx, nil, err
+		}
+	}
+}
+
+// sleep waits for d on the API's Clock unless ctx ends first.
+func (a *API) sleep(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if d <= 0 {
+		return nil
+	}
+	t := a.clock().NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C():
+		return nil
+	}
+}
@@ -0,0 +1,256 @@
+package grepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy_delay(t *testing.T) {
+	p := &RetryPolicy{Backoff: 100 * time.Millisecond, MaxBackoff: time.Second, Jitter: 0.5}
+	tests := []struct {
+		attempt int
+		r       float64
+		want    time.Duration
+	}{
+		{attempt: 1, r: 0.5, want: 100 * time.Millisecond},
+		{attempt: 2, r: 0.5, want: 200 * time.Millisecond},
+		{attempt: 3, r: 0, want: 200 * time.Millisecond},
+		{attempt: 3, r: 1, want: 600 * time.Millisecond},
+		{attempt: 10, r: 0.5, want: time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("attempt %d r %v", tt.attempt, tt.r), func(t *testing.T) {
+			if got := p.delay(tt.attempt, tt.r); got != tt.want {
+				t.Errorf("delay() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type flakyOutput struct {
+	Attempts int
+}
+
+// flakyUseCase fails with err until it has been called failures times.
+type flakyUseCase struct {
+	failures int
+	err      error
+	attempts []int
+}
+
+func (u *flakyUseCase) Execute(ctx context.Context, input TestInput) (*flakyOutput, error) {
+	u.attempts = append(u.attempts, Attempt(ctx))
+	if len(u.attempts) <= u.failures {
+		return nil, u.err
+	}
+	return &flakyOutput{Attempts: len(u.attempts)}, nil
+}
+
+func TestAPI_WithRetryPolicy(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3}
+
+	tests := []struct {
+		name         string
+		uc           *flakyUseCase
+		build        func(b *UseCaseBuilder[TestInput, flakyOutput]) *UseCaseBuilder[TestInput, flakyOutput]
+		wantAttempts []int
+		wantHooked   int
+		wantErr      error
+	}{
+		{
+			name: "正常系: 失敗後にリトライして成功",
+			uc:   &flakyUseCase{failures: 2, err: ErrUnavailable},
+			build: func(b *UseCaseBuilder[TestInput, flakyOutput]) *UseCaseBuilder[TestInput, flakyOutput] {
+				return b.WithIdempotent().WithRetryPolicy(policy)
+			},
+			wantAttempts: []int{1, 2, 3},
+		},
+		{
+			name: "異常系: 最大試行回数で諦める",
+			uc:   &flakyUseCase{failures: 5, err: ErrUnavailable},
+			build: func(b *UseCaseBuilder[TestInput, flakyOutput]) *UseCaseBuilder[TestInput, flakyOutput] {
+				return b.WithIdempotent().WithRetryPolicy(policy)
+			},
+			wantAttempts: []int{1, 2, 3},
+			wantHooked:   3,
+			wantErr:      ErrUnavailable,
+		},
+		{
+			name: "異常系: リトライ対象外のエラー",
+			uc:   &flakyUseCase{failures: 1, err: ErrConflict},
+			build: func(b *UseCaseBuilder[TestInput, flakyOutput]) *UseCaseBuilder[TestInput, flakyOutput] {
+				return b.WithIdempotent().WithRetryPolicy(policy)
+			},
+			wantAttempts: []int{1},
+			wantHooked:   1,
+			wantErr:      ErrConflict,
+		},
+		{
+			name: "正常系: Retryableで対象エラーを指定",
+			uc:   &flakyUseCase{failures: 1, err: fmt.Errorf("lock: %w", ErrConflict)},
+			build: func(b *UseCaseBuilder[TestInput, flakyOutput]) *UseCaseBuilder[TestInput, flakyOutput] {
+				return b.WithIdempotent().WithRetryPolicy(RetryPolicy{MaxAttempts: 2, Retryable: RetryOn(ErrConflict)})
+			},
+			wantAttempts: []int{1, 2},
+		},
+		{
+			name: "正常系: Groupのポリシー",
+			uc:   &flakyUseCase{failures: 1, err: ErrUnavailable},
+			build: func(b *UseCaseBuilder[TestInput, flakyOutput]) *UseCaseBuilder[TestInput, flakyOutput] {
+				return b.WithIdempotent().WithGroup(NewGroup("g").WithRetryPolicy(policy))
+			},
+			wantAttempts: []int{1, 2},
+		},
+		{
+			name: "異常系: 冪等でない操作にはGroupのポリシーを適用しない",
+			uc:   &flakyUseCase{failures: 1, err: ErrUnavailable},
+			build: func(b *UseCaseBuilder[TestInput, flakyOutput]) *UseCaseBuilder[TestInput, flakyOutput] {
+				return b.WithGroup(NewGroup("g").WithRetryPolicy(policy))
+			},
+			wantAttempts: []int{1},
+			wantHooked:   1,
+			wantErr:      ErrUnavailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hooked := 0
+			api := NewAPIBuilder().
+				AddErrorHook(func(ctx context.Context, desc Descriptor, i any, err error) {
+					hooked = Attempt(ctx)
+				}).
+				AddUseCase(tt.build(NewUseCaseBuilder(tt.uc).WithOperation("flaky")).Build()).
+				Build()
+
+			_, err := api.ExecuteAny(context.Background(), "flaky", TestInput{})
+			if !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+				t.Errorf("ExecuteAny() error = %v, want %v", err, tt.wantErr)
+			}
+			if fmt.Sprint(tt.uc.attempts) != fmt.Sprint(tt.wantAttempts) {
+				t.Errorf("attempts = %v, want %v", tt.uc.attempts, tt.wantAttempts)
+			}
+			if hooked != tt.wantHooked {
+				t.Errorf("error hook attempt = %v, want %v", hooked, tt.wantHooked)
+			}
+		})
+	}
+
+	t.Run("異常系: 冪等でない操作のポリシーはBuildEでエラー", func(t *testing.T) {
+		_, err := NewAPIBuilder().
+			AddUseCase(NewUseCaseBuilder(&flakyUseCase{}).WithOperation("flaky").WithRetryPolicy(policy).Build()).
+			BuildE()
+		if err == nil || !strings.Contains(err.Error(), "flaky: retry policy requires an idempotent operation") {
+			t.Errorf("BuildE() error = %v", err)
+		}
+	})
+
+	t.Run("正常系: バックオフをClockで待機する", func(t *testing.T) {
+		clock := NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+		uc := &flakyUseCase{failures: 2, err: ErrUnavailable}
+		api := NewAPIBuilder().
+			WithOptions(WithClock(clock)).
+			AddUseCase(NewUseCaseBuilder(uc).
+				WithOperation("flaky").
+				WithIdempotent().
+				WithRetryPolicy(RetryPolicy{MaxAttempts: 3, Backoff: time.Second}).
+				Build()).
+			Build()
+
+		done := make(chan error)
+		go func() {
+			_, err := api.ExecuteAny(context.Background(), "flaky", TestInput{})
+			done <- err
+		}()
+
+		for _, backoff := range []time.Duration{time.Second, 2 * time.Second} {
+			clock.BlockUntil(1)
+			clock.Advance(backoff - time.Millisecond)
+			select {
+			case err := <-done:
+				t.Fatalf("ExecuteAny() returned %v before the backoff elapsed", err)
+			default:
+			}
+			clock.Advance(time.Millisecond)
+		}
+		if err := <-done; err != nil {
+			t.Errorf("ExecuteAny() error = %v", err)
+		}
+		if fmt.Sprint(uc.attempts) != "[1 2 3]" {
+			t.Errorf("attempts = %v", uc.attempts)
+		}
+	})
+
+	t.Run("正常系: Randでジッターを固定する", func(t *testing.T) {
+		clock := NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+		uc := &flakyUseCase{failures: 3, err: ErrUnavailable}
+		rs := []float64{0, 1, 0.75}
+		api := NewAPIBuilder().
+			WithOptions(WithClock(clock)).
+			AddUseCase(NewUseCaseBuilder(uc).
+				WithOperation("flaky").
+				WithIdempotent().
+				WithRetryPolicy(RetryPolicy{
+					MaxAttempts: 4,
+					Backoff:     time.Second,
+					Jitter:      0.5,
+					Rand: func() float64 {
+						r := rs[0]
+						rs = rs[1:]
+						return r
+					},
+				}).
+				Build()).
+			Build()
+
+		done := make(chan error)
+		go func() {
+			_, err := api.ExecuteAny(context.Background(), "flaky", TestInput{})
+			done <- err
+		}()
+
+		for _, want := range []time.Duration{500 * time.Millisecond, 3 * time.Second, 5 * time.Second} {
+			clock.BlockUntil(1)
+			clock.mu.Lock()
+			got := clock.timers[0].at.Sub(clock.now)
+			clock.mu.Unlock()
+			if got != want {
+				t.Errorf("backoff = %v, want %v", got, want)
+			}
+			clock.Advance(got)
+		}
+		if err := <-done; err != nil {
+			t.Errorf("ExecuteAny() error = %v", err)
+		}
+	})
+
+	t.Run("異常系: 待機中のタイムアウトで中断する", func(t *testing.T) {
+		clock := NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+		uc := &flakyUseCase{failures: 5, err: ErrUnavailable}
+		api := NewAPIBuilder().
+			WithOptions(WithClock(clock)).
+			AddUseCase(NewUseCaseBuilder(uc).
+				WithOperation("flaky").
+				WithIdempotent().
+				WithRetryPolicy(RetryPolicy{MaxAttempts: 3, Backoff: time.Hour}).
+				WithTimeout(time.Minute).
+				Build()).
+			Build()
+
+		done := make(chan error)
+		go func() {
+			_, err := api.ExecuteAny(context.Background(), "flaky", TestInput{})
+			done <- err
+		}()
+		// The timeout and the backoff timers.
+		clock.BlockUntil(2)
+		clock.Advance(time.Minute)
+
+		if err := <-done; !errors.Is(err, ErrUnavailable) || len(uc.attempts) != 1 {
+			t.Errorf("ExecuteAny() error = %v after %v attempts", err, uc.attempts)
+		}
+	})
+}
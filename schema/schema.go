@@ -0,0 +1,261 @@
+package schema
+
+import (
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/ralsnet/grepo/refl"
+)
+
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 any                `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Default              any                `json:"default,omitempty"`
+	Examples             []any              `json:"examples,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	MinProperties        *int               `json:"minProperties,omitempty"`
+	MaxProperties        *int               `json:"maxProperties,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+}
+
+func For(t *refl.Type) *Schema {
+	g := NewGenerator("#/$defs/")
+	s := g.SchemaOf(t)
+	s.Schema = Draft
+	if defs := g.Defs(); len(defs) > 0 {
+		s.Defs = defs
+	}
+	return s
+}
+
+type Generator struct {
+	refPrefix string
+	defs      map[string]*Schema
+	// names maps a type to its definition name and owners a definition name
+	// to its type, or to "" for one added by Define.
+	names  map[string]string
+	owners map[string]string
+}
+
+func NewGenerator(refPrefix string) *Generator {
+	return &Generator{
+		refPrefix: refPrefix,
+		defs:      make(map[string]*Schema),
+		names:     make(map[string]string),
+		owners:    make(map[string]string),
+	}
+}
+
+func (g *Generator) Defs() map[string]*Schema {
+	return g.defs
+}
+
+func (g *Generator) Define(name string, s *Schema) *Schema {
+	g.defs[name] = s
+	g.owners[name] = ""
+	return &Schema{Ref: g.Ref(name)}
+}
+
+func (g *Generator) Ref(name string) string {
+	return g.refPrefix + name
+}
+
+func (g *Generator) SchemaOf(t *refl.Type) *Schema {
+	s := g.schemaOf(t)
+	if !t.Pointer {
+		return s
+	}
+	if s.Ref != "" {
+		return &Schema{AnyOf: []*Schema{s, {Type: "null"}}}
+	}
+	if typ, ok := s.Type.(string); ok {
+		s.Type = []string{typ, "null"}
+	}
+	return s
+}
+
+func (g *Generator) schemaOf(t *refl.Type) *Schema {
+	switch t.Kind {
+	case refl.KindObject:
+		name := g.defName(t)
+		if name == "" {
+			return g.objectSchema(t)
+		}
+		if _, ok := g.defs[name]; !ok {
+			// Register the name before walking the fields so that repeated
+			// references resolve to the same definition.
+			g.defs[name] = &Schema{}
+			*g.defs[name] = *g.objectSchema(t)
+		}
+		return &Schema{Ref: g.Ref(name)}
+	case refl.KindArray:
+		return &Schema{Type: "array", Items: g.SchemaOf(t.Element)}
+	case refl.KindMap:
+		return &Schema{Type: "object", AdditionalProperties: g.SchemaOf(t.Element)}
+	case refl.KindString:
+		return &Schema{Type: "string"}
+	case refl.KindInt, refl.KindInt64, refl.KindUint, refl.KindUint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case refl.KindInt8, refl.KindInt16, refl.KindInt32, refl.KindUint8, refl.KindUint16, refl.KindUint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case refl.KindFloat32:
+		return &Schema{Type: "number", Format: "float"}
+	case refl.KindFloat64:
+		return &Schema{Type: "number", Format: "double"}
+	case refl.KindBool:
+		return &Schema{Type: "boolean"}
+	case refl.KindTime:
+		return &Schema{Type: "string", Format: "date-time"}
+	default:
+		return &Schema{}
+	}
+}
+
+func (g *Generator) objectSchema(t *refl.Type) *Schema {
+	s := &Schema{
+		Type:       "object",
+		Properties: make(map[string]*Schema),
+	}
+	for _, f := range t.Fields {
+		fs := g.SchemaOf(f.Type)
+		if len(f.Enum) > 0 {
+			fs.Enum = enumValues(f.Type.Kind, f.Enum)
+			if f.Type.Pointer {
+				fs.Enum = append(fs.Enum, nil)
+			}
+		}
+		fs.Minimum = f.Min
+		fs.Maximum = f.Max
+		applyLength(fs, f)
+		fs.Pattern = f.Pattern
+		if f.Format != "" {
+			fs.Format = f.Format
+		}
+		fs.Description = f.Description
+		if v, ok := f.DefaultValue(); ok {
+			fs.Default = v.Interface()
+		}
+		if v, ok := f.ExampleValue(); ok {
+			fs.Examples = []any{v.Interface()}
+		}
+		s.Properties[f.Name] = fs
+		if !f.Optional {
+			s.Required = append(s.Required, f.Name)
+		}
+	}
+	return s
+}
+
+func applyLength(s *Schema, f *refl.Field) {
+	switch f.Type.Kind {
+	case refl.KindArray:
+		s.MinItems = f.MinLen
+		s.MaxItems = f.MaxLen
+	case refl.KindMap:
+		s.MinProperties = f.MinLen
+		s.MaxProperties = f.MaxLen
+	default:
+		s.MinLength = f.MinLen
+		s.MaxLength = f.MaxLen
+	}
+	if f.MinItems != nil {
+		s.MinItems = f.MinItems
+	}
+	if f.MaxItems != nil {
+		s.MaxItems = f.MaxItems
+	}
+}
+
+// defName returns the name under which t is defined, or "" for a type that
+// is not named. Names only contain the characters allowed in OpenAPI
+// component names, so they are also valid in a JSON Pointer. A name that is
+// already taken by another type is qualified with the package path, then
+// numbered.
+func (g *Generator) defName(t *refl.Type) string {
+	name := strings.TrimLeft(t.Name, "*")
+	if name == "" || strings.HasSuffix(name, ".") {
+		return ""
+	}
+	id := t.Pkg + " " + name
+	if n, ok := g.names[id]; ok {
+		return n
+	}
+
+	n := sanitizeName(name)
+	if _, ok := g.owners[n]; ok && t.Pkg != "" {
+		base := strings.TrimPrefix(name, path.Base(t.Pkg)+".")
+		n = sanitizeName(t.Pkg + "." + base)
+	}
+	for i, base := 2, n; ; i++ {
+		if _, ok := g.owners[n]; !ok {
+			break
+		}
+		n = base + "_" + strconv.Itoa(i)
+	}
+	g.owners[n] = id
+	g.names[id] = n
+	return n
+}
+
+// sanitizeName maps a Go type name onto [a-zA-Z0-9._-]. Package separators
+// become dots and the brackets and commas of type arguments underscores, so
+// that sub.Page[example.com/sub.User] becomes sub.Page_example.com.sub.User.
+func sanitizeName(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			b.WriteRune(r)
+		case r == '/':
+			b.WriteByte('.')
+		case r == ']':
+		default:
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
+
+func enumValues(kind string, values []string) []any {
+	enum := make([]any, 0, len(values))
+	for _, v := range values {
+		switch kind {
+		case refl.KindInt, refl.KindInt8, refl.KindInt16, refl.KindInt32, refl.KindInt64,
+			refl.KindUint, refl.KindUint8, refl.KindUint16, refl.KindUint32, refl.KindUint64:
+			if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+				enum = append(enum, n)
+				continue
+			}
+		case refl.KindFloat32, refl.KindFloat64:
+			if n, err := strconv.ParseFloat(v, 64); err == nil {
+				enum = append(enum, n)
+				continue
+			}
+		case refl.KindBool:
+			if b, err := strconv.ParseBool(v); err == nil {
+				enum = append(enum, b)
+				continue
+			}
+		}
+		enum = append(enum, v)
+	}
+	return enum
+}
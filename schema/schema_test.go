@@ -0,0 +1,154 @@
+package schema
+
+import (
+	"encoding/json"
+	"regexp"
+	"testing"
+
+	"github.com/ralsnet/grepo/refl"
+)
+
+type testGroup struct {
+	Name string `json:"name"`
+}
+
+type testUser struct {
+	ID     string       `json:"id"`
+	Role   *string      `json:"role" grepo:"optional:true;enum:admin,user"`
+	Age    *int         `json:"age" grepo:"optional:true;min:0"`
+	Groups []*testGroup `json:"groups"`
+}
+
+type testNode struct {
+	Name     string      `json:"name"`
+	Children []*testNode `json:"children"`
+}
+
+type testPage[T any] struct {
+	Items []T `json:"items"`
+}
+
+func TestFor(t *testing.T) {
+	tests := []struct {
+		name string
+		v    any
+		want string
+	}{
+		{
+			name: "正常系: プリミティブ型",
+			v:    "",
+			want: `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"string"}`,
+		},
+		{
+			name: "正常系: 配列型",
+			v:    []int{},
+			want: `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"array","items":{"type":"integer","format":"int64"}}`,
+		},
+		{
+			name: "正常系: 名前付き構造体は$defsに集約される",
+			v:    testUser{},
+			want: `{"$schema":"https://json-schema.org/draft/2020-12/schema","$ref":"#/$defs/schema.testUser","$defs":{` +
+				`"schema.testGroup":{"type":"object","properties":{"name":{"type":"string"}},"required":["name"]},` +
+				`"schema.testUser":{"type":"object","properties":{` +
+				`"age":{"type":["integer","null"],"format":"int64","minimum":0},` +
+				`"groups":{"type":"array","items":{"anyOf":[{"$ref":"#/$defs/schema.testGroup"},{"type":"null"}]}},` +
+				`"id":{"type":"string"},` +
+				`"role":{"type":["string","null"],"enum":["admin","user",null]}` +
+				`},"required":["id","groups"]}}}`,
+		},
+		{
+			name: "正常系: 再帰的な型は自身の定義を参照する",
+			v:    testNode{},
+			want: `{"$schema":"https://json-schema.org/draft/2020-12/schema","$ref":"#/$defs/schema.testNode","$defs":{` +
+				`"schema.testNode":{"type":"object","properties":{` +
+				`"children":{"type":"array","items":{"anyOf":[{"$ref":"#/$defs/schema.testNode"},{"type":"null"}]}},` +
+				`"name":{"type":"string"}` +
+				`},"required":["name","children"]}}}`,
+		},
+		{
+			name: "正常系: ジェネリック型の名前は使用可能な文字に置き換える",
+			v:    testPage[testGroup]{},
+			want: `{"$schema":"https://json-schema.org/draft/2020-12/schema","$ref":"#/$defs/schema.testPage_github.com.ralsnet.grepo.schema.testGroup","$defs":{` +
+				`"schema.testGroup":{"type":"object","properties":{"name":{"type":"string"}},"required":["name"]},` +
+				`"schema.testPage_github.com.ralsnet.grepo.schema.testGroup":{"type":"object","properties":{` +
+				`"items":{"type":"array","items":{"$ref":"#/$defs/schema.testGroup"}}` +
+				`},"required":["items"]}}}`,
+		},
+		{
+			name: "正常系: 説明・デフォルト値・例",
+			v: struct {
+				Limit *int     `json:"limit" grepo:"optional;default:20;example:50;desc:Page size"`
+				Sort  []string `json:"sort" doc:"Sort keys; first wins" grepo:"optional;default:[\"name\"]"`
+			}{},
+			want: `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","properties":{` +
+				`"limit":{"type":["integer","null"],"format":"int64","description":"Page size","default":20,"examples":[50]},` +
+				`"sort":{"type":"array","description":"Sort keys; first wins","default":["name"],"items":{"type":"string"}}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := json.Marshal(For(refl.TypeOf(tt.v)))
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+			if string(b) != tt.want {
+				t.Errorf("For() = %s, want %s", b, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerator_DefName(t *testing.T) {
+	valid := regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
+	g := NewGenerator("#/components/schemas/")
+	g.Define("Error", &Schema{})
+	tests := []struct {
+		name string
+		t    *refl.Type
+		want string
+	}{
+		{
+			name: "正常系: 型引数を含む名前",
+			t:    &refl.Type{Kind: refl.KindObject, Pkg: "example.com/sub", Name: "*sub.Page[example.com/sub.User]"},
+			want: "sub.Page_example.com.sub.User",
+		},
+		{
+			name: "正常系: 同じ型は同じ名前",
+			t:    &refl.Type{Kind: refl.KindObject, Pkg: "example.com/sub", Name: "sub.Page[example.com/sub.User]"},
+			want: "sub.Page_example.com.sub.User",
+		},
+		{
+			name: "正常系: 置き換え後に衝突する名前はパッケージで修飾する",
+			t:    &refl.Type{Kind: refl.KindObject, Pkg: "example.com/sub", Name: "sub.Page_example.com.sub.User"},
+			want: "example.com.sub.Page_example.com.sub.User",
+		},
+		{
+			name: "正常系: パッケージで区別できなければ番号を付ける",
+			t:    &refl.Type{Kind: refl.KindObject, Pkg: "example.com/sub", Name: "sub.Page[example.com/sub.User"},
+			want: "example.com.sub.Page_example.com.sub.User_2",
+		},
+		{
+			name: "正常系: 別パッケージの同名の型はパッケージで修飾する",
+			t:    &refl.Type{Kind: refl.KindObject, Pkg: "example.com/other/sub", Name: "sub.Page[example.com/sub.User]"},
+			want: "example.com.other.sub.Page_example.com.sub.User",
+		},
+		{
+			name: "正常系: Defineした名前と衝突しない",
+			t:    &refl.Type{Kind: refl.KindObject, Name: "Error"},
+			want: "Error_2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := g.defName(tt.t)
+			if got != tt.want {
+				t.Errorf("defName() = %v, want %v", got, tt.want)
+			}
+			if !valid.MatchString(got) {
+				t.Errorf("defName() = %v, not a valid component name", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/ralsnet/grepo"
+)
+
+// Attribute keys set on execution spans.
+const (
+	AttrOperation   = "grepo.operation"
+	AttrGroups      = "grepo.groups"
+	AttrExecutionID = "grepo.execution_id"
+	AttrTransport   = "grepo.transport"
+	AttrPrincipal   = "grepo.principal"
+	AttrAttempts    = "grepo.attempts"
+	AttrOutcome     = "grepo.outcome"
+	AttrErrorCode   = "grepo.error_code"
+)
+
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+)
+
+type executionSpan struct {
+	id   string
+	span Span
+}
+
+// HookBefore starts a span per execution, named after the operation. Add it
+// as the first root before hook so that the span covers the other hooks.
+func HookBefore(tracer Tracer) grepo.BeforeHook[any] {
+	return func(ctx context.Context, desc grepo.Descriptor, i any) (context.Context, error) {
+		ctx, _ = start(ctx, tracer, desc)
+		return ctx, nil
+	}
+}
+
+// HookAfter ends the execution span started by HookBefore.
+func HookAfter() grepo.AfterHook[any, any] {
+	return func(ctx context.Context, desc grepo.Descriptor, i any, o any) {
+		span := spanOf(ctx)
+		if span == nil {
+			return
+		}
+		span.SetAttributes(Attr(AttrOutcome, OutcomeSuccess), Attr(AttrAttempts, grepo.Attempt(ctx)))
+		span.SetStatus(StatusOK, "")
+		span.End()
+	}
+}
+
+// HookError records err on the execution span and ends it. Executions that
+// failed before HookBefore ran, during authorization for instance, get a span
+// of their own.
+func HookError(tracer Tracer) grepo.ErrorHook[any] {
+	return func(ctx context.Context, desc grepo.Descriptor, i any, err error) {
+		span := spanOf(ctx)
+		if span == nil {
+			_, span = start(ctx, tracer, desc)
+		}
+		span.RecordError(err)
+		span.SetAttributes(
+			Attr(AttrOutcome, OutcomeError),
+			Attr(AttrErrorCode, string(grepo.CodeOf(err))),
+			Attr(AttrAttempts, grepo.Attempt(ctx)),
+		)
+		span.SetStatus(StatusError, err.Error())
+		span.End()
+	}
+}
+
+func start(ctx context.Context, tracer Tracer, desc grepo.Descriptor) (context.Context, Span) {
+	groups := make([]string, 0, len(desc.Groups()))
+	for _, g := range desc.Groups() {
+		groups = append(groups, g.Name())
+	}
+	attrs := []Attribute{
+		Attr(AttrOperation, desc.Operation()),
+		Attr(AttrGroups, groups),
+	}
+	info := grepo.ExecutionInfoFrom(ctx)
+	if info != nil {
+		attrs = append(attrs, Attr(AttrExecutionID, info.ID), Attr(AttrTransport, info.Transport))
+		if info.Principal != nil {
+			attrs = append(attrs, Attr(AttrPrincipal, info.Principal.ID))
+		}
+	}
+
+	ctx, span := tracer.Start(ctx, desc.Operation(), attrs...)
+	ctx = ContextWithSpan(ctx, span)
+	if info != nil {
+		ctx = context.WithValue(ctx, ctxkeyExecutionSpan, &executionSpan{id: info.ID, span: span})
+	}
+	return ctx, span
+}
+
+// spanOf returns the span started for the current execution, ignoring the
+// span of an enclosing execution.
+func spanOf(ctx context.Context) Span {
+	es, ok := ctx.Value(ctxkeyExecutionSpan).(*executionSpan)
+	if !ok {
+		return nil
+	}
+	if info := grepo.ExecutionInfoFrom(ctx); info == nil || info.ID != es.id {
+		return nil
+	}
+	return es.span
+}
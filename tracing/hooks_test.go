@@ -0,0 +1,158 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ralsnet/grepo"
+)
+
+type input struct {
+	Value int
+}
+
+type output struct {
+	Value int
+}
+
+type protectedUseCase struct{}
+
+func (u *protectedUseCase) Execute(ctx context.Context, in input) (*output, error) {
+	return &output{}, nil
+}
+
+type outerUseCase struct {
+	api func() *grepo.API
+}
+
+func (u *outerUseCase) Execute(ctx context.Context, in input) (*output, error) {
+	grepo.ClockFrom(ctx).(*grepo.FakeClock).Advance(time.Second)
+	if _, err := u.api().ExecuteAny(ctx, "inner", in); err != nil {
+		return nil, err
+	}
+	return &output{Value: in.Value}, nil
+}
+
+type innerUseCase struct{}
+
+func (u *innerUseCase) Execute(ctx context.Context, in input) (*output, error) {
+	if in.Value < 0 {
+		return nil, grepo.NewError(grepo.CodeInvalid, "negative value")
+	}
+	return &output{}, nil
+}
+
+func TestHooks(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	recorder := NewRecorder()
+	ids := 0
+	var api *grepo.API
+	api = grepo.NewAPIBuilder().
+		WithOptions(
+			grepo.WithClock(grepo.NewFakeClock(start)),
+			grepo.WithIDGenerator(func() string {
+				ids++
+				return fmt.Sprintf("exec-%d", ids)
+			}),
+		).
+		AddBeforeHook(HookBefore(recorder)).
+		AddBeforeHook(func(ctx context.Context, desc grepo.Descriptor, i any) (context.Context, error) {
+			if desc.Operation() == "rejected" {
+				grepo.ClockFrom(ctx).(*grepo.FakeClock).Advance(time.Second)
+				return nil, grepo.NewError(grepo.CodeUnavailable, "maintenance")
+			}
+			return ctx, nil
+		}).
+		AddAfterHook(HookAfter()).
+		AddErrorHook(HookError(recorder)).
+		AddUseCase(grepo.NewUseCaseBuilder(&outerUseCase{api: func() *grepo.API { return api }}).
+			WithOperation("outer").
+			WithGroup(grepo.NewGroup("users")).
+			Build()).
+		AddUseCase(grepo.NewUseCaseBuilder(&innerUseCase{}).WithOperation("inner").Build()).
+		AddUseCase(grepo.NewUseCaseBuilder(&innerUseCase{}).WithOperation("rejected").Build()).
+		AddUseCase(grepo.NewUseCaseBuilder(&protectedUseCase{}).
+			WithOperation("protected").
+			WithRoles("admin").
+			Build()).
+		Build()
+
+	remote, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := ContextWithRemoteSpanContext(grepo.WithTransport(context.Background(), grepo.TransportTest), remote)
+
+	t.Run("正常系: ネストしたスパン", func(t *testing.T) {
+		recorder.Reset()
+		if _, err := api.ExecuteAny(ctx, "outer", input{Value: 1}); err != nil {
+			t.Fatalf("ExecuteAny() error = %v", err)
+		}
+		spans := recorder.Spans()
+		if len(spans) != 2 {
+			t.Fatalf("spans = %+v", spans)
+		}
+		inner, outer := spans[0], spans[1]
+
+		if outer.Name != "outer" || outer.Parent != remote || outer.SpanContext.TraceID != remote.TraceID {
+			t.Errorf("outer = %+v", outer)
+		}
+		if inner.Name != "inner" || inner.Parent != outer.SpanContext || inner.SpanContext.TraceID != remote.TraceID {
+			t.Errorf("inner = %+v", inner)
+		}
+		if got := fmt.Sprint(outer.Attributes); got != "map[grepo.attempts:1 grepo.execution_id:exec-1 grepo.groups:[users] grepo.operation:outer grepo.outcome:success grepo.transport:test]" {
+			t.Errorf("outer.Attributes = %v", got)
+		}
+		if outer.Status != StatusOK || outer.EndTime.Sub(outer.StartTime) != time.Second {
+			t.Errorf("outer = %+v", outer)
+		}
+	})
+
+	t.Run("異常系: ネストした実行のエラー", func(t *testing.T) {
+		recorder.Reset()
+		if _, err := api.ExecuteAny(ctx, "outer", input{Value: -1}); err == nil {
+			t.Fatal("ExecuteAny() error = nil")
+		}
+		spans := recorder.Spans()
+		if len(spans) != 2 {
+			t.Fatalf("spans = %+v", spans)
+		}
+		for _, span := range spans {
+			if span.Status != StatusError || span.Attributes[AttrErrorCode] != "Invalid" || len(span.Errors) != 1 {
+				t.Errorf("span = %+v", span)
+			}
+		}
+		if spans[0].Parent != spans[1].SpanContext {
+			t.Errorf("inner parent = %v, want %v", spans[0].Parent, spans[1].SpanContext)
+		}
+	})
+
+	t.Run("異常系: 後続のBeforeHookのエラーで開始済みのスパンを終了する", func(t *testing.T) {
+		recorder.Reset()
+		if _, err := api.ExecuteAny(ctx, "rejected", input{}); err == nil {
+			t.Fatal("ExecuteAny() error = nil")
+		}
+		spans := recorder.Spans()
+		if len(spans) != 1 {
+			t.Fatalf("spans = %+v", spans)
+		}
+		if span := spans[0]; span.Name != "rejected" || span.Parent != remote || span.Status != StatusError ||
+			span.Attributes[AttrErrorCode] != "Unavailable" || len(span.Errors) != 1 {
+			t.Errorf("span = %+v", span)
+		}
+		// The span started by HookBefore, not a new one started by HookError.
+		if d := spans[0].EndTime.Sub(spans[0].StartTime); d != time.Second {
+			t.Errorf("span duration = %v, want %v", d, time.Second)
+		}
+	})
+
+	t.Run("異常系: BeforeHook前の認可エラー", func(t *testing.T) {
+		recorder.Reset()
+		if _, err := api.ExecuteAny(ctx, "protected", input{}); err == nil {
+			t.Fatal("ExecuteAny() error = nil")
+		}
+		spans := recorder.Spans()
+		if len(spans) != 1 || spans[0].Name != "protected" || spans[0].Attributes[AttrErrorCode] != "Unauthenticated" {
+			t.Errorf("spans = %+v", spans)
+		}
+	})
+}
@@ -0,0 +1,123 @@
+package tracing
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/ralsnet/grepo"
+)
+
+// RecordedSpan is a snapshot of a span ended on a Recorder.
+type RecordedSpan struct {
+	Name              string
+	SpanContext       SpanContext
+	Parent            SpanContext
+	Attributes        map[string]any
+	Status            StatusCode
+	StatusDescription string
+	Errors            []error
+	StartTime         time.Time
+	EndTime           time.Time
+}
+
+// Recorder is an in-memory Tracer for tests. Span times come from the
+// grepo.Clock of the context the span is started with.
+type Recorder struct {
+	mu    sync.Mutex
+	spans []RecordedSpan
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+func (r *Recorder) Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	parent := SpanContextFromContext(ctx)
+	sc := SpanContext{TraceID: parent.TraceID, SpanID: NewSpanID(), Sampled: true}
+	if parent.IsValid() {
+		sc.Sampled = parent.Sampled
+	} else {
+		sc.TraceID = NewTraceID()
+	}
+
+	clock := grepo.ClockFrom(ctx)
+	span := &recordingSpan{
+		recorder: r,
+		clock:    clock,
+		data: RecordedSpan{
+			Name:        name,
+			SpanContext: sc,
+			Parent:      parent,
+			Attributes:  make(map[string]any),
+			StartTime:   clock.Now(),
+		},
+	}
+	span.SetAttributes(attrs...)
+	return ContextWithSpan(ctx, span), span
+}
+
+// Spans returns the ended spans in the order they ended.
+func (r *Recorder) Spans() []RecordedSpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]RecordedSpan(nil), r.spans...)
+}
+
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = nil
+}
+
+type recordingSpan struct {
+	mu       sync.Mutex
+	recorder *Recorder
+	clock    grepo.Clock
+	data     RecordedSpan
+	ended    bool
+}
+
+func (s *recordingSpan) SpanContext() SpanContext {
+	return s.data.SpanContext
+}
+
+func (s *recordingSpan) SetAttributes(attrs ...Attribute) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, attr := range attrs {
+		s.data.Attributes[attr.Key] = attr.Value
+	}
+}
+
+func (s *recordingSpan) RecordError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Errors = append(s.data.Errors, err)
+}
+
+func (s *recordingSpan) SetStatus(code StatusCode, description string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Status = code
+	s.data.StatusDescription = description
+}
+
+func (s *recordingSpan) End() {
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.EndTime = s.clock.Now()
+	data := s.data
+	data.Attributes = maps.Clone(s.data.Attributes)
+	data.Errors = append([]error(nil), s.data.Errors...)
+	s.mu.Unlock()
+
+	s.recorder.mu.Lock()
+	defer s.recorder.mu.Unlock()
+	s.recorder.spans = append(s.recorder.spans, data)
+}
@@ -0,0 +1,66 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		wantErr     bool
+		wantSampled bool
+	}{
+		{name: "正常系: sampled", value: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", wantSampled: true},
+		{name: "正常系: not sampled", value: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"},
+		{name: "正常系: 将来のバージョン", value: "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", wantSampled: true},
+		{name: "異常系: 不正なバージョン", value: "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", wantErr: true},
+		{name: "異常系: ゼロのトレースID", value: "00-00000000000000000000000000000000-00f067aa0ba902b7-01", wantErr: true},
+		{name: "異常系: 大文字", value: "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", wantErr: true},
+		{name: "異常系: 長さ不正", value: "00-4bf92f3577b34da6a3ce929d0e0e473-00f067aa0ba902b7-01", wantErr: true},
+		{name: "異常系: 余分なフィールド", value: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc, err := ParseTraceparent(tt.value)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidTraceparent) {
+					t.Errorf("ParseTraceparent() error = %v, want ErrInvalidTraceparent", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseTraceparent() error = %v", err)
+			}
+			if !sc.Remote || sc.Sampled != tt.wantSampled {
+				t.Errorf("SpanContext = %+v", sc)
+			}
+			if got := sc.TraceID.String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+				t.Errorf("TraceID = %v", got)
+			}
+			if got := sc.SpanID.String(); got != "00f067aa0ba902b7" {
+				t.Errorf("SpanID = %v", got)
+			}
+		})
+	}
+}
+
+func TestTraceparent(t *testing.T) {
+	if got := Traceparent(context.Background()); got != "" {
+		t.Errorf("Traceparent() = %q, want empty", got)
+	}
+
+	const value = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	sc, _ := ParseTraceparent(value)
+	ctx := ContextWithRemoteSpanContext(context.Background(), sc)
+	if got := Traceparent(ctx); got != value {
+		t.Errorf("Traceparent() = %q, want %q", got, value)
+	}
+
+	ctx, span := NewRecorder().Start(ctx, "child")
+	if got := SpanContextFromContext(ctx); got != span.SpanContext() || got.TraceID != sc.TraceID || got.Remote {
+		t.Errorf("SpanContextFromContext() = %+v", got)
+	}
+}
@@ -1,12 +1,15 @@
 package grepo
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"reflect"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/ralsnet/grepo/refl"
 )
@@ -15,6 +18,7 @@ type UseCaseHook[I any, O any] struct {
 	before []BeforeHook[*I]
 	after  []AfterHook[I, *O]
 	error  []ErrorHook[I]
+	around []Middleware[I, *O]
 }
 
 func NewUseCaseHook[I any, O any]() *UseCaseHook[I, O] {
@@ -36,6 +40,11 @@ func (h *UseCaseHook[I, O]) AddError(hook func(ctx context.Context, uc Descripto
 	return h
 }
 
+func (h *UseCaseHook[I, O]) AddMiddleware(m func(ctx context.Context, uc Descriptor, i I, next Next[I, *O]) (*O, error)) *UseCaseHook[I, O] {
+	h.around = append(h.around, m)
+	return h
+}
+
 type Executor[I any, O any] interface {
 	Execute(ctx context.Context, input I) (*O, error)
 }
@@ -60,11 +69,22 @@ type Descriptor interface {
 }
 
 type Interactor[I any, O any] struct {
-	uc     Executor[I, O]
-	op     string
-	desc   string
-	hook   *UseCaseHook[I, O]
-	groups []*Group
+	uc           Executor[I, O]
+	op           string
+	desc         string
+	hook         *UseCaseHook[I, O]
+	groups       []*Group
+	codes        []Code
+	timeout      time.Duration
+	idempotent   bool
+	retry        *RetryPolicy
+	requirements Requirements
+	auditable    bool
+	tags         []string
+	deprecated   string
+	readOnly     bool
+	experimental bool
+	examples     []Example
 }
 
 func newInteractor[I any, O any](uc Executor[I, O]) *Interactor[I, O] {
@@ -105,6 +125,72 @@ func (i *Interactor[I, O]) DoErrorHook(ctx context.Context, input I, err error)
 	}
 }
 
+// DoMiddleware runs next wrapped by the use case's middleware, the first one
+// added being the outermost.
+func (i *Interactor[I, O]) DoMiddleware(ctx context.Context, input I, next Next[I, *O]) (*O, error) {
+	for j := len(i.hook.around) - 1; j >= 0; j-- {
+		m, n := i.hook.around[j], next
+		next = func(ctx context.Context, input I) (*O, error) {
+			return m(ctx, i, input, n)
+		}
+	}
+	return next(ctx, input)
+}
+
+func (i *Interactor[I, O]) beforeAny(ctx context.Context, input any) (context.Context, any, error) {
+	in, ok := input.(I)
+	if !ok {
+		return ctx, input, fmt.Errorf("%w: input type %T, want %T", ErrInvalid, input, in)
+	}
+	ctx, err := i.DoBeforeHook(ctx, &in)
+	return ctx, in, err
+}
+
+func (i *Interactor[I, O]) executeAny(ctx context.Context, input any) (any, error) {
+	in, ok := input.(I)
+	if !ok {
+		return nil, fmt.Errorf("%w: input type %T, want %T", ErrInvalid, input, in)
+	}
+	out, err := i.Execute(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (i *Interactor[I, O]) aroundAny(ctx context.Context, input any, next Next[any, any]) (any, error) {
+	in, ok := input.(I)
+	if !ok {
+		return nil, fmt.Errorf("%w: input type %T, want %T", ErrInvalid, input, in)
+	}
+	out, err := i.DoMiddleware(ctx, in, func(ctx context.Context, in I) (*O, error) {
+		out, err := next(ctx, in)
+		if err != nil {
+			return nil, err
+		}
+		o, ok := out.(*O)
+		if !ok && out != nil {
+			return nil, fmt.Errorf("%w: output type %T, want %T", ErrInvalid, out, o)
+		}
+		return o, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (i *Interactor[I, O]) afterAny(ctx context.Context, input any, output any) {
+	in, _ := input.(I)
+	out, _ := output.(*O)
+	i.DoAfterHook(ctx, in, out)
+}
+
+func (i *Interactor[I, O]) errorAny(ctx context.Context, input any, err error) {
+	in, _ := input.(I)
+	i.DoErrorHook(ctx, in, err)
+}
+
 func (i *Interactor[I, O]) Operation() string {
 	if i.op == "" {
 		rt := reflect.TypeOf(i.uc)
@@ -134,6 +220,77 @@ func (i *Interactor[I, O]) Groups() []*Group {
 	return i.groups
 }
 
+// Timeout is the use case's own timeout, overriding its groups and the API
+// default.
+func (i *Interactor[I, O]) Timeout() time.Duration {
+	return i.timeout
+}
+
+// Idempotent reports whether executing the use case more than once with the
+// same input has the same effect as executing it once.
+func (i *Interactor[I, O]) Idempotent() bool {
+	return i.idempotent
+}
+
+func (i *Interactor[I, O]) RetryPolicy() *RetryPolicy {
+	return i.retry
+}
+
+// Requirements are the use case's own requirements, without those of its
+// groups.
+func (i *Interactor[I, O]) Requirements() Requirements {
+	return i.requirements
+}
+
+// Auditable reports whether the use case itself is flagged for auditing. Use
+// IsAuditable to take its groups into account.
+func (i *Interactor[I, O]) Auditable() bool {
+	return i.auditable
+}
+
+// IsAuditable reports whether executions of d must be audited, because d or
+// one of its groups is flagged with WithAuditable.
+func IsAuditable(d Descriptor) bool {
+	if a, ok := d.(interface{ Auditable() bool }); ok && a.Auditable() {
+		return true
+	}
+	for _, g := range d.Groups() {
+		if g.Auditable() {
+			return true
+		}
+	}
+	return false
+}
+
+func (i *Interactor[I, O]) Tags() []string {
+	return i.tags
+}
+
+// Deprecated is the deprecation message of the use case, or "" if it is not
+// deprecated.
+func (i *Interactor[I, O]) Deprecated() string {
+	return i.deprecated
+}
+
+// ReadOnly reports whether the use case leaves state unchanged. Use cases that
+// are not read-only are mutating.
+func (i *Interactor[I, O]) ReadOnly() bool {
+	return i.readOnly
+}
+
+func (i *Interactor[I, O]) Experimental() bool {
+	return i.experimental
+}
+
+func (i *Interactor[I, O]) Examples() []Example {
+	return i.examples
+}
+
+// ErrorCodes lists the error codes the use case declares it may return.
+func (i *Interactor[I, O]) ErrorCodes() []Code {
+	return i.codes
+}
+
 func (i *Interactor[I, O]) MarshalJSON() ([]byte, error) {
 	b := strings.Builder{}
 
@@ -159,6 +316,36 @@ func (i *Interactor[I, O]) MarshalJSON() ([]byte, error) {
 	b.WriteString(",")
 	b.WriteString(fmt.Sprintf("%q: %s", "Groups", groupsJSON))
 
+	if tags := i.Tags(); len(tags) > 0 {
+		tagsJSON, _ := json.Marshal(tags)
+		b.WriteString(",")
+		b.WriteString(fmt.Sprintf("%q: %s", "Tags", tagsJSON))
+	}
+	if deprecated := i.Deprecated(); deprecated != "" {
+		b.WriteString(",")
+		b.WriteString(fmt.Sprintf("%q: %q", "Deprecated", deprecated))
+	}
+	if i.ReadOnly() {
+		b.WriteString(",")
+		b.WriteString(fmt.Sprintf("%q: true", "ReadOnly"))
+	}
+	if i.Idempotent() {
+		b.WriteString(",")
+		b.WriteString(fmt.Sprintf("%q: true", "Idempotent"))
+	}
+	if i.Experimental() {
+		b.WriteString(",")
+		b.WriteString(fmt.Sprintf("%q: true", "Experimental"))
+	}
+	if examples := i.Examples(); len(examples) > 0 {
+		examplesJSON, err := json.Marshal(examples)
+		if err != nil {
+			return nil, err
+		}
+		b.WriteString(",")
+		b.WriteString(fmt.Sprintf("%q: %s", "Examples", examplesJSON))
+	}
+
 	b.WriteString("}")
 
 	return []byte(b.String()), nil
@@ -210,11 +397,94 @@ func (b *UseCaseBuilder[I, O]) AddErrorHook(hook func(ctx context.Context, i I,
 	return b
 }
 
+func (b *UseCaseBuilder[I, O]) AddMiddleware(m func(ctx context.Context, i I, next Next[I, *O]) (*O, error)) *UseCaseBuilder[I, O] {
+	b.uc.hook.AddMiddleware(func(ctx context.Context, uc Descriptor, i I, next Next[I, *O]) (*O, error) {
+		return m(ctx, i, next)
+	})
+	return b
+}
+
 func (b *UseCaseBuilder[I, O]) WithGroup(group *Group) *UseCaseBuilder[I, O] {
 	b.uc.groups = append(b.uc.groups, group)
 	return b
 }
 
+func (b *UseCaseBuilder[I, O]) WithErrorCodes(codes ...Code) *UseCaseBuilder[I, O] {
+	for _, code := range codes {
+		if !slices.Contains(b.uc.codes, code) {
+			b.uc.codes = append(b.uc.codes, code)
+		}
+	}
+	return b
+}
+
+func (b *UseCaseBuilder[I, O]) WithTimeout(d time.Duration) *UseCaseBuilder[I, O] {
+	b.uc.timeout = d
+	return b
+}
+
+func (b *UseCaseBuilder[I, O]) WithIdempotent() *UseCaseBuilder[I, O] {
+	b.uc.idempotent = true
+	return b
+}
+
+// WithRetryPolicy retries failed executions. The use case must also be
+// marked WithIdempotent, otherwise the API fails to build.
+func (b *UseCaseBuilder[I, O]) WithRetryPolicy(p RetryPolicy) *UseCaseBuilder[I, O] {
+	b.uc.retry = &p
+	return b
+}
+
+func (b *UseCaseBuilder[I, O]) WithRoles(roles ...string) *UseCaseBuilder[I, O] {
+	b.uc.requirements = b.uc.requirements.merge(Requirements{Roles: roles})
+	return b
+}
+
+func (b *UseCaseBuilder[I, O]) WithPermissions(perms ...string) *UseCaseBuilder[I, O] {
+	b.uc.requirements = b.uc.requirements.merge(Requirements{Permissions: perms})
+	return b
+}
+
+// WithAuditable flags the use case for the audit hooks, typically because it
+// mutates state that regulations require a trail of.
+func (b *UseCaseBuilder[I, O]) WithAuditable() *UseCaseBuilder[I, O] {
+	b.uc.auditable = true
+	return b
+}
+
+func (b *UseCaseBuilder[I, O]) WithTags(tags ...string) *UseCaseBuilder[I, O] {
+	b.uc.tags = append(b.uc.tags, tags...)
+	return b
+}
+
+// WithDeprecated marks the use case as deprecated. The message should tell
+// callers what to use instead; an empty one is replaced by "deprecated".
+func (b *UseCaseBuilder[I, O]) WithDeprecated(message string) *UseCaseBuilder[I, O] {
+	b.uc.deprecated = cmp.Or(message, "deprecated")
+	return b
+}
+
+// WithReadOnly declares that the use case leaves state unchanged, which also
+// makes it idempotent.
+func (b *UseCaseBuilder[I, O]) WithReadOnly() *UseCaseBuilder[I, O] {
+	b.uc.readOnly = true
+	b.uc.idempotent = true
+	return b
+}
+
+// WithExperimental declares that the use case may change or go away without
+// deprecation.
+func (b *UseCaseBuilder[I, O]) WithExperimental() *UseCaseBuilder[I, O] {
+	b.uc.experimental = true
+	return b
+}
+
+// AddExample documents a sample execution of the use case.
+func (b *UseCaseBuilder[I, O]) AddExample(name string, input I, output O) *UseCaseBuilder[I, O] {
+	b.uc.examples = append(b.uc.examples, Example{Name: name, Input: input, Output: output})
+	return b
+}
+
 func (b *UseCaseBuilder[I, O]) Build() *Interactor[I, O] {
 	return b.uc
 }
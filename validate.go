@@ -1,9 +1,18 @@
 package grepo
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"net/mail"
+	"net/url"
 	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
 
 	"github.com/ralsnet/grepo/refl"
 )
@@ -18,75 +27,301 @@ func (fn FieldValidatorFunc) Validate(v reflect.Value, f *refl.Field) error {
 	return fn(v, f)
 }
 
+// StructValidator is implemented by inputs and outputs that need rules spanning
+// several fields. It runs after the tag constraints of the struct's fields.
+type StructValidator interface {
+	Validate(ctx context.Context) error
+}
+
+type StructValidatorFunc func(ctx context.Context, v any) error
+
+const (
+	ConstraintRequired = "required"
+	ConstraintEnum     = "enum"
+	ConstraintMin      = "min"
+	ConstraintMax      = "max"
+	ConstraintMinLen   = "minLen"
+	ConstraintMaxLen   = "maxLen"
+	ConstraintMinItems = "minItems"
+	ConstraintMaxItems = "maxItems"
+	ConstraintPattern  = "pattern"
+	ConstraintFormat   = "format"
+	ConstraintTag      = "tag"
+	ConstraintStruct   = "struct"
+	ConstraintCustom   = "custom"
+)
+
+type Violation struct {
+	Path       string
+	Constraint string
+	Value      any
+	Message    string
+}
+
+func (v *Violation) Error() string {
+	if v.Path == "" {
+		return v.Message
+	}
+	return fmt.Sprintf("field %s %s", v.Path, v.Message)
+}
+
+type ValidationError struct {
+	Violations []*Violation
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		msgs = append(msgs, v.Error())
+	}
+	return fmt.Sprintf("%s: %s", ErrInvalid, strings.Join(msgs, "; "))
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrInvalid
+}
+
+func (e *ValidationError) add(path string, constraint string, v reflect.Value, err error) {
+	var violation *Violation
+	if errors.As(err, &violation) {
+		vv := *violation
+		violation = &vv
+	} else {
+		violation = &Violation{
+			Message: err.Error(),
+		}
+	}
+	if violation.Path == "" {
+		violation.Path = path
+	}
+	if violation.Constraint == "" {
+		violation.Constraint = constraint
+	}
+	if violation.Value == nil && v.IsValid() && v.CanInterface() {
+		violation.Value = v.Interface()
+	}
+	e.Violations = append(e.Violations, violation)
+}
+
 func Validate(v any, validators ...FieldValidator) error {
-	rv := reflect.ValueOf(v)
-	if err := validate(rv, validators...); err != nil {
-		return errors.Join(ErrInvalid, err)
+	return ValidateContext(context.Background(), v, validators...)
+}
+
+func ValidateContext(ctx context.Context, v any, validators ...FieldValidator) error {
+	return validateWith(ctx, v, nil, &validator{validators: validators})
+}
+
+// validateWith validates v, whose static type is t. t may be nil when it is
+// not known in advance.
+func validateWith(ctx context.Context, v any, t *refl.Type, vd *validator) error {
+	vd.ctx = ctx
+	vd.verr = &ValidationError{}
+	vd.validate(reflect.ValueOf(v), t, "")
+	if len(vd.verr.Violations) > 0 {
+		return vd.verr
 	}
 	return nil
 }
 
-func validate(v reflect.Value, validators ...FieldValidator) error {
+type validator struct {
+	validators []FieldValidator
+	named      map[string]FieldValidator
+	structs    map[reflect.Type][]StructValidatorFunc
+	types      *sync.Map
+	ctx        context.Context
+	verr       *ValidationError
+	sensitive  bool
+}
+
+func (vd *validator) typeOf(rt reflect.Type) *refl.Type {
+	if vd.types == nil {
+		return refl.TypeFor(rt)
+	}
+	if t, ok := vd.types.Load(rt); ok {
+		return t.(*refl.Type)
+	}
+	t, _ := vd.types.LoadOrStore(rt, refl.TypeFor(rt))
+	return t.(*refl.Type)
+}
+
+func (vd *validator) validate(v reflect.Value, t *refl.Type, path string) {
 	if !v.IsValid() {
-		return fmt.Errorf("invalid value")
+		vd.verr.add(path, ConstraintRequired, v, errors.New("is invalid"))
+		return
 	}
-	for v.Kind() == reflect.Pointer {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
 		if v.IsNil() {
-			return nil
+			return
 		}
 		v = v.Elem()
 	}
 
-	t := refl.TypeOf(v.Interface())
+	if t == nil || t.Kind == refl.KindUnknown {
+		t = vd.typeOf(v.Type())
+	}
+	t = t.Resolve()
 	switch t.Kind {
 	case refl.KindObject:
 		for _, ft := range t.Fields {
-			fv := v.FieldByName(ft.Field)
-			if err := validateField(fv, ft, validators...); err != nil {
-				return err
+			fv, err := v.FieldByIndexErr(ft.Index)
+			if err != nil {
+				fv = reflect.Zero(v.Type().FieldByIndex(ft.Index).Type)
 			}
+			vd.validateField(fv, ft, fieldPath(path, ft.Name))
 		}
+		vd.validateStruct(v, path)
 	case refl.KindArray:
 		for i := 0; i < v.Len(); i++ {
-			if err := validate(v.Index(i)); err != nil {
-				return err
-			}
+			vd.validate(v.Index(i), t.Element, fmt.Sprintf("%s[%d]", path, i))
+		}
+	case refl.KindMap:
+		keys := v.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int {
+			return strings.Compare(fmt.Sprint(a.Interface()), fmt.Sprint(b.Interface()))
+		})
+		for _, key := range keys {
+			vd.validate(v.MapIndex(key), t.Element, mapPath(path, key))
 		}
 	}
-
-	return nil
 }
 
-func validateField(v reflect.Value, f *refl.Field, validators ...FieldValidator) error {
+func (vd *validator) validateField(v reflect.Value, f *refl.Field, path string) {
+	if f.Sensitive || vd.sensitive {
+		// Violations end up in logs and responses, so they must not carry the
+		// value of a sensitive field or of anything nested in it.
+		if !f.Sensitive {
+			sf := *f
+			sf.Sensitive = true
+			f = &sf
+		}
+		n := len(vd.verr.Violations)
+		sensitive := vd.sensitive
+		vd.sensitive = true
+		defer func() {
+			vd.sensitive = sensitive
+			for _, violation := range vd.verr.Violations[n:] {
+				violation.Value = nil
+			}
+		}()
+	}
 	if !v.IsValid() {
-		return fmt.Errorf("field %s is required but invalid", f.Field)
+		vd.verr.add(path, ConstraintRequired, v, errors.New("is required but invalid"))
+		return
 	}
 
 	rv := v
-	for rv.Kind() == reflect.Pointer {
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
 		if rv.IsNil() {
 			break
 		}
 		rv = rv.Elem()
 	}
 
-	vs := make([]FieldValidator, 0, len(validators)+3)
-	vs = append(vs, validators...)
-	vs = append(vs, FieldValidatorFunc(validateOptional))
+	if err := f.Err(); err != nil {
+		vd.verr.add(path, ConstraintTag, rv, fmt.Errorf("has malformed tag: %v", err))
+		return
+	}
+
+	if err := validateOptional(rv, f); err != nil {
+		vd.verr.add(path, ConstraintRequired, rv, err)
+		return
+	}
+	if f.Optional && isEmpty(v) {
+		// An omitted optional field has no value for constraints to check. A
+		// non-nil pointer counts as given even when it points to zero.
+		return
+	}
+
+	vs := make([]FieldValidator, 0, len(vd.validators)+5)
+	vs = append(vs, vd.validators...)
 	vs = append(vs, FieldValidatorFunc(validateEnum))
 	vs = append(vs, FieldValidatorFunc(validateMinMax))
+	vs = append(vs, FieldValidatorFunc(validateLength))
+	vs = append(vs, FieldValidatorFunc(validatePattern))
+	vs = append(vs, FieldValidatorFunc(validateFormat))
 
 	for _, validator := range vs {
 		if err := validator.Validate(rv, f); err != nil {
-			return err
+			vd.verr.add(path, ConstraintCustom, rv, err)
 		}
 	}
 
-	if err := validate(rv, validators...); err != nil {
-		return err
+	for _, name := range f.Custom {
+		validator, ok := vd.named[name]
+		if !ok {
+			vd.verr.add(path, name, rv, fmt.Errorf("has unknown custom validator %q", name))
+			continue
+		}
+		if err := validator.Validate(rv, f); err != nil {
+			vd.verr.add(path, name, rv, err)
+		}
 	}
 
-	return nil
+	vd.validate(rv, f.Type, path)
+}
+
+func (vd *validator) validateStruct(v reflect.Value, path string) {
+	var errs []error
+	if sv, ok := structValidatorOf(v); ok {
+		errs = append(errs, sv.Validate(vd.ctx))
+	}
+	for _, fn := range vd.structs[v.Type()] {
+		errs = append(errs, fn(vd.ctx, v.Interface()))
+	}
+
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		var verr *ValidationError
+		if errors.As(err, &verr) {
+			for _, violation := range verr.Violations {
+				vv := *violation
+				vv.Path = fieldPath(path, vv.Path)
+				vd.verr.Violations = append(vd.verr.Violations, &vv)
+			}
+			continue
+		}
+		var violation *Violation
+		if errors.As(err, &violation) {
+			vv := *violation
+			vv.Path = fieldPath(path, vv.Path)
+			err = &vv
+		}
+		vd.verr.add(path, ConstraintStruct, reflect.Value{}, err)
+	}
+}
+
+var structValidatorType = reflect.TypeFor[StructValidator]()
+
+func structValidatorOf(v reflect.Value) (StructValidator, bool) {
+	if !v.CanInterface() || !reflect.PointerTo(v.Type()).Implements(structValidatorType) {
+		return nil, false
+	}
+	if sv, ok := v.Interface().(StructValidator); ok {
+		return sv, true
+	}
+	p := reflect.New(v.Type())
+	p.Elem().Set(v)
+	sv, ok := p.Interface().(StructValidator)
+	return sv, ok
+}
+
+func fieldPath(parent string, name string) string {
+	if parent == "" {
+		return name
+	}
+	if name == "" {
+		return parent
+	}
+	return parent + "." + name
+}
+
+func mapPath(parent string, key reflect.Value) string {
+	if key.Kind() == reflect.String {
+		return fmt.Sprintf("%s[%q]", parent, key.String())
+	}
+	return fmt.Sprintf("%s[%v]", parent, key.Interface())
 }
 
 func validateOptional(v reflect.Value, f *refl.Field) error {
@@ -108,21 +343,31 @@ func validateOptional(v reflect.Value, f *refl.Field) error {
 		}
 	}
 	if v.IsZero() {
-		return fmt.Errorf("field %s is required but zero", f.Field)
+		return &Violation{Constraint: ConstraintRequired, Message: "is required but zero"}
 	}
 	if (v.Kind() == reflect.Slice || v.Kind() == reflect.Map) && v.Len() == 0 {
-		return fmt.Errorf("field %s is required but empty", f.Field)
+		return &Violation{Constraint: ConstraintRequired, Message: "is required but empty"}
 	}
 	return nil
 }
 
+func isEmpty(v reflect.Value) bool {
+	if v.IsZero() {
+		return true
+	}
+	return (v.Kind() == reflect.Slice || v.Kind() == reflect.Map) && v.Len() == 0
+}
+
 func validateEnum(v reflect.Value, f *refl.Field) error {
 	if len(f.Enum) == 0 {
 		return nil
 	}
+	if v.Kind() == reflect.Pointer && v.IsNil() {
+		return nil
+	}
 
-	if f.Type.Kind == refl.KindObject || f.Type.Kind == refl.KindArray {
-		return fmt.Errorf("field %s has enum constraint but is complex type", f.Field)
+	if f.Type.Kind == refl.KindObject || f.Type.Kind == refl.KindArray || f.Type.Kind == refl.KindMap {
+		return &Violation{Constraint: ConstraintEnum, Message: "has enum constraint but is complex type"}
 	}
 	switch {
 	case v.CanInt():
@@ -147,41 +392,104 @@ func validateEnum(v reflect.Value, f *refl.Field) error {
 			}
 		}
 	}
-	return fmt.Errorf("field %s has value %s which is not in enum %v", f.Field, v.String(), f.Enum)
+	return &Violation{Constraint: ConstraintEnum, Message: fmt.Sprintf("%s which is not in enum %v", hasValue(f, v.String()), f.Enum)}
+}
+
+// hasValue describes the value of a field in a violation message, leaving the
+// value out when the field is sensitive.
+func hasValue(f *refl.Field, v any) string {
+	if f.Sensitive {
+		return "has value"
+	}
+	return fmt.Sprintf("has value %v", v)
 }
 
 func validateMinMax(v reflect.Value, f *refl.Field) error {
-	if f.Min != nil {
-		switch {
-		case v.CanInt():
-			if v.Int() < int64(*f.Min) {
-				return fmt.Errorf("field %s has value %d which is less than min %d", f.Field, v.Int(), *f.Min)
-			}
-		case v.CanUint():
-			if v.Uint() < uint64(*f.Min) {
-				return fmt.Errorf("field %s has value %d which is less than min %d", f.Field, v.Uint(), *f.Min)
-			}
-		case v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64:
-			if v.Float() < float64(*f.Min) {
-				return fmt.Errorf("field %s has value %f which is less than min %d", f.Field, v.Float(), *f.Min)
-			}
-		}
+	if f.Min == nil && f.Max == nil {
+		return nil
 	}
-	if f.Max != nil {
-		switch {
-		case v.CanInt():
-			if v.Int() > int64(*f.Max) {
-				return fmt.Errorf("field %s has value %d which is greater than max %d", f.Field, v.Int(), *f.Max)
-			}
-		case v.CanUint():
-			if v.Uint() > uint64(*f.Max) {
-				return fmt.Errorf("field %s has value %d which is greater than max %d", f.Field, v.Uint(), *f.Max)
-			}
-		case v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64:
-			if v.Float() > float64(*f.Max) {
-				return fmt.Errorf("field %s has value %f which is greater than max %d", f.Field, v.Float(), *f.Max)
-			}
-		}
+	var n float64
+	switch {
+	case v.CanInt():
+		n = float64(v.Int())
+	case v.CanUint():
+		n = float64(v.Uint())
+	case v.CanFloat():
+		n = v.Float()
+	default:
+		return nil
+	}
+	if f.Min != nil && n < *f.Min {
+		return &Violation{Constraint: ConstraintMin, Message: fmt.Sprintf("%s which is less than min %v", hasValue(f, n), *f.Min)}
+	}
+	if f.Max != nil && n > *f.Max {
+		return &Violation{Constraint: ConstraintMax, Message: fmt.Sprintf("%s which is greater than max %v", hasValue(f, n), *f.Max)}
+	}
+	return nil
+}
+
+func validateLength(v reflect.Value, f *refl.Field) error {
+	var n int
+	switch v.Kind() {
+	case reflect.String:
+		n = utf8.RuneCountInString(v.String())
+	case reflect.Slice, reflect.Array, reflect.Map:
+		n = v.Len()
+	default:
+		return nil
+	}
+	if f.MinLen != nil && n < *f.MinLen {
+		return &Violation{Constraint: ConstraintMinLen, Message: fmt.Sprintf("has length %d which is less than minLen %d", n, *f.MinLen)}
+	}
+	if f.MaxLen != nil && n > *f.MaxLen {
+		return &Violation{Constraint: ConstraintMaxLen, Message: fmt.Sprintf("has length %d which is greater than maxLen %d", n, *f.MaxLen)}
+	}
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil
+	}
+	if f.MinItems != nil && n < *f.MinItems {
+		return &Violation{Constraint: ConstraintMinItems, Message: fmt.Sprintf("has %d items which is less than minItems %d", n, *f.MinItems)}
+	}
+	if f.MaxItems != nil && n > *f.MaxItems {
+		return &Violation{Constraint: ConstraintMaxItems, Message: fmt.Sprintf("has %d items which is greater than maxItems %d", n, *f.MaxItems)}
+	}
+	return nil
+}
+
+func validatePattern(v reflect.Value, f *refl.Field) error {
+	re := f.Regexp()
+	if re == nil || v.Kind() != reflect.String {
+		return nil
+	}
+	if !re.MatchString(v.String()) {
+		return &Violation{Constraint: ConstraintPattern, Message: fmt.Sprintf("%s which does not match pattern %s", hasValue(f, v.String()), f.Pattern)}
+	}
+	return nil
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+func validateFormat(v reflect.Value, f *refl.Field) error {
+	if f.Format == "" || v.Kind() != reflect.String {
+		return nil
+	}
+	s := v.String()
+	valid := true
+	switch f.Format {
+	case refl.FormatEmail:
+		addr, err := mail.ParseAddress(s)
+		valid = err == nil && addr.Address == s
+	case refl.FormatUUID:
+		valid = uuidPattern.MatchString(s)
+	case refl.FormatURI:
+		u, err := url.Parse(s)
+		valid = err == nil && u.Scheme != ""
+	case refl.FormatDate:
+		_, err := time.Parse(time.DateOnly, s)
+		valid = err == nil
+	}
+	if !valid {
+		return &Violation{Constraint: ConstraintFormat, Message: fmt.Sprintf("%s which is not a valid %s", hasValue(f, s), f.Format)}
 	}
 	return nil
 }
@@ -0,0 +1,376 @@
+package grepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ralsnet/grepo/refl"
+)
+
+type validateBase struct {
+	ID string `json:"id"`
+}
+
+type validateTaggedInput struct {
+	*validateBase
+	Role string `json:"role" grepo:"enum:admin,user"`
+	Note string `json:"-"`
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		v       any
+		wantErr string
+	}{
+		{
+			name: "正常系: 埋め込みフィールドを含めて有効",
+			v:    validateTaggedInput{validateBase: &validateBase{ID: "1"}, Role: "admin"},
+		},
+		{
+			name:    "異常系: エラーメッセージはjsonタグ名を使う",
+			v:       validateTaggedInput{validateBase: &validateBase{ID: "1"}, Role: "root"},
+			wantErr: "field role",
+		},
+		{
+			name:    "異常系: nilの埋め込みポインタのフィールドは必須エラー",
+			v:       validateTaggedInput{Role: "admin"},
+			wantErr: "field id",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.v)
+			if (err != nil) != (tt.wantErr != "") {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil {
+				return
+			}
+			if !errors.Is(err, ErrInvalid) {
+				t.Errorf("Validate() error = %v, want ErrInvalid", err)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want contains %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+type validateGroup struct {
+	Name string `json:"name"`
+}
+
+type validateUser struct {
+	Name   string           `json:"name"`
+	Age    int              `json:"age" grepo:"min:0;max:150"`
+	Groups []*validateGroup `json:"groups" grepo:"optional:true"`
+}
+
+type validateUsersInput struct {
+	Users []*validateUser `json:"users"`
+}
+
+func TestValidate_Violations(t *testing.T) {
+	input := validateUsersInput{
+		Users: []*validateUser{
+			{Name: "a", Age: 10},
+			{Name: "", Age: 200, Groups: []*validateGroup{{Name: "g"}, {Name: ""}}},
+		},
+	}
+
+	err := Validate(input)
+	if !errors.Is(err, ErrInvalid) {
+		t.Fatalf("Validate() error = %v, want ErrInvalid", err)
+	}
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Validate() error = %T, want *ValidationError", err)
+	}
+
+	want := []Violation{
+		{Path: "users[1].name", Constraint: ConstraintRequired, Value: ""},
+		{Path: "users[1].age", Constraint: ConstraintMax, Value: 200},
+		{Path: "users[1].groups[1].name", Constraint: ConstraintRequired, Value: ""},
+	}
+	if len(verr.Violations) != len(want) {
+		t.Fatalf("Violations = %v, want %v", verr.Violations, want)
+	}
+	for i, w := range want {
+		got := verr.Violations[i]
+		if got.Path != w.Path || got.Constraint != w.Constraint || got.Value != w.Value {
+			t.Errorf("Violations[%d] = %+v, want %+v", i, got, w)
+		}
+	}
+}
+
+type validateSensitiveInput struct {
+	Password string       `json:"password" grepo:"sensitive;minLen:8"`
+	Role     string       `json:"role" grepo:"sensitive;enum:admin,user"`
+	Card     validateCard `json:"card" grepo:"sensitive"`
+	Name     string       `json:"name" grepo:"maxLen:3"`
+}
+
+type validateCard struct {
+	Number string `json:"number" grepo:"pattern:^[0-9]+$"`
+}
+
+func TestValidate_Sensitive(t *testing.T) {
+	err := Validate(validateSensitiveInput{Password: "secret", Role: "root", Card: validateCard{Number: "x-1"}, Name: "long"})
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Validate() error = %v, want *ValidationError", err)
+	}
+
+	want := []Violation{
+		{Path: "password", Constraint: ConstraintMinLen},
+		{Path: "role", Constraint: ConstraintEnum},
+		{Path: "card.number", Constraint: ConstraintPattern},
+		{Path: "name", Constraint: ConstraintMaxLen, Value: "long"},
+	}
+	if len(verr.Violations) != len(want) {
+		t.Fatalf("Violations = %v, want %v", verr.Violations, want)
+	}
+	for i, w := range want {
+		got := verr.Violations[i]
+		if got.Path != w.Path || got.Constraint != w.Constraint || got.Value != w.Value {
+			t.Errorf("Violations[%d] = %+v, want %+v", i, got, w)
+		}
+	}
+	for _, secret := range []string{"secret", "root", "x-1"} {
+		if strings.Contains(err.Error(), secret) {
+			t.Errorf("Validate() error = %v, contains %q", err, secret)
+		}
+	}
+}
+
+type validateNestedInput struct {
+	Users    []*validateUser          `json:"users" grepo:"optional:true"`
+	ByID     map[string]*validateUser `json:"byId" grepo:"optional:true"`
+	Matrix   [][]*validateGroup       `json:"matrix" grepo:"optional:true"`
+	Level    **int                    `json:"level" grepo:"optional:true;min:1"`
+	Anything map[string]any           `json:"anything" grepo:"optional:true"`
+}
+
+func TestValidate_Nested(t *testing.T) {
+	zero := 0
+	zeroPtr := &zero
+
+	tests := []struct {
+		name       string
+		v          any
+		wantPaths  []string
+		wantCustom []string
+	}{
+		{
+			name: "正常系: FindUsersOutput形式の[]*User",
+			v: validateNestedInput{
+				Users: []*validateUser{{Name: "a", Groups: []*validateGroup{{Name: "g"}}}},
+			},
+			wantCustom: []string{"users", "name", "age", "groups", "name"},
+		},
+		{
+			name: "異常系: スライス・マップ・ポインタの深い階層",
+			v: validateNestedInput{
+				Users: []*validateUser{nil, {Name: "", Age: 1}},
+				ByID: map[string]*validateUser{
+					"b": {Name: "b", Age: -1},
+					"a": {Name: ""},
+				},
+				Matrix:   [][]*validateGroup{{{Name: "x"}}, {{Name: ""}}},
+				Level:    &zeroPtr,
+				Anything: map[string]any{"u": &validateUser{Name: ""}},
+			},
+			wantPaths: []string{
+				"users[1].name",
+				`byId["a"].name`,
+				`byId["b"].age`,
+				"matrix[1][0].name",
+				"level",
+				`anything["u"].name`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var custom []string
+			validator := FieldValidatorFunc(func(v reflect.Value, f *refl.Field) error {
+				custom = append(custom, f.Name)
+				return nil
+			})
+			err := Validate(tt.v, validator)
+
+			var paths []string
+			var verr *ValidationError
+			if errors.As(err, &verr) {
+				for _, v := range verr.Violations {
+					paths = append(paths, v.Path)
+				}
+			} else if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			if strings.Join(paths, ",") != strings.Join(tt.wantPaths, ",") {
+				t.Errorf("Violations paths = %v, want %v", paths, tt.wantPaths)
+			}
+			if tt.wantCustom != nil && strings.Join(custom, ",") != strings.Join(tt.wantCustom, ",") {
+				t.Errorf("custom validator called for %v, want %v", custom, tt.wantCustom)
+			}
+		})
+	}
+}
+
+type validateConstraintInput struct {
+	Slug    string   `json:"slug" grepo:"optional;pattern:^[a-z0-9-]+$"`
+	Name    string   `json:"name" grepo:"optional;minLen:2;maxLen:4"`
+	Email   string   `json:"email" grepo:"optional;format:email"`
+	ID      string   `json:"id" grepo:"optional;format:uuid"`
+	URL     string   `json:"url" grepo:"optional;format:uri"`
+	Date    string   `json:"date" grepo:"optional;format:date"`
+	Ratio   float64  `json:"ratio" grepo:"optional;min:0.5;max:1.5"`
+	Tags    []string `json:"tags" grepo:"optional;minItems:1;maxItems:2"`
+	Matches []string `json:"matches" grepo:"optional;maxLen:1"`
+}
+
+type validateMalformedInput struct {
+	Value int `json:"value" grepo:"min:abc"`
+}
+
+func TestValidate_Constraints(t *testing.T) {
+	valid := validateConstraintInput{
+		Slug:  "a-b-1",
+		Name:  "日本語",
+		Email: "a@example.com",
+		ID:    "019bb5be-cfa3-7f05-8434-f5e3a55dc73b",
+		URL:   "https://example.com/a",
+		Date:  "2024-02-29",
+		Ratio: 1,
+		Tags:  []string{"a"},
+	}
+
+	tests := []struct {
+		name           string
+		v              any
+		wantConstraint string
+	}{
+		{name: "正常系: 全ての制約を満たす", v: valid},
+		{name: "正常系: 省略した任意フィールドには制約を適用しない", v: validateConstraintInput{}},
+		{name: "正常系: 空スライスの任意フィールドにはminItemsを適用しない", v: validateConstraintInput{Tags: []string{}}},
+		{name: "異常系: pattern", v: func() any { v := valid; v.Slug = "A B"; return v }(), wantConstraint: ConstraintPattern},
+		{name: "異常系: minLen", v: func() any { v := valid; v.Name = "a"; return v }(), wantConstraint: ConstraintMinLen},
+		{name: "異常系: maxLen", v: func() any { v := valid; v.Name = "abcde"; return v }(), wantConstraint: ConstraintMaxLen},
+		{name: "異常系: format email", v: func() any { v := valid; v.Email = "Bob <a@example.com>"; return v }(), wantConstraint: ConstraintFormat},
+		{name: "異常系: format uuid", v: func() any { v := valid; v.ID = "not-a-uuid"; return v }(), wantConstraint: ConstraintFormat},
+		{name: "異常系: format uri", v: func() any { v := valid; v.URL = "example.com"; return v }(), wantConstraint: ConstraintFormat},
+		{name: "異常系: format date", v: func() any { v := valid; v.Date = "2023-02-29"; return v }(), wantConstraint: ConstraintFormat},
+		{name: "異常系: 小数のmin", v: func() any { v := valid; v.Ratio = 0.25; return v }(), wantConstraint: ConstraintMin},
+		{name: "異常系: 小数のmax", v: func() any { v := valid; v.Ratio = 1.75; return v }(), wantConstraint: ConstraintMax},
+		{name: "異常系: maxItems", v: func() any { v := valid; v.Tags = []string{"a", "b", "c"}; return v }(), wantConstraint: ConstraintMaxItems},
+		{name: "異常系: スライスのmaxLen", v: func() any { v := valid; v.Matches = []string{"a", "b"}; return v }(), wantConstraint: ConstraintMaxLen},
+		{name: "異常系: 不正なタグ", v: validateMalformedInput{Value: 1}, wantConstraint: ConstraintTag},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.v)
+			if tt.wantConstraint == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("Validate() error = %v, want *ValidationError", err)
+			}
+			if len(verr.Violations) != 1 || verr.Violations[0].Constraint != tt.wantConstraint {
+				t.Errorf("Violations = %v, want one %s violation", verr.Violations, tt.wantConstraint)
+			}
+		})
+	}
+}
+
+type validatePeriod struct {
+	StartAt time.Time `json:"startAt"`
+	EndAt   time.Time `json:"endAt"`
+}
+
+func (p *validatePeriod) Validate(ctx context.Context) error {
+	if !p.EndAt.After(p.StartAt) {
+		return &Violation{Path: "endAt", Message: "must be after startAt"}
+	}
+	if p.StartAt.Before(ExecuteTime(ctx)) {
+		return errors.New("must not start in the past")
+	}
+	return nil
+}
+
+type validateSearchInput struct {
+	IDs     []string         `json:"ids" grepo:"optional"`
+	Name    string           `json:"name" grepo:"optional"`
+	Periods []validatePeriod `json:"periods" grepo:"optional"`
+}
+
+func TestValidate_Struct(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	options := &APIOptions{}
+	WithStructValidator(func(ctx context.Context, in validateSearchInput) error {
+		if len(in.IDs) == 0 && in.Name == "" {
+			return errors.New("either ids or name must be set")
+		}
+		return nil
+	})(options)
+
+	tests := []struct {
+		name      string
+		v         validateSearchInput
+		wantPaths []string
+	}{
+		{
+			name: "正常系: 全ての規則を満たす",
+			v:    validateSearchInput{Name: "a", Periods: []validatePeriod{{StartAt: now, EndAt: now.Add(time.Hour)}}},
+		},
+		{
+			name:      "異常系: WithStructValidatorで登録した規則",
+			v:         validateSearchInput{},
+			wantPaths: []string{""},
+		},
+		{
+			name: "異常系: ネストした構造体のValidateメソッドとコンテキスト",
+			v: validateSearchInput{IDs: []string{"1"}, Periods: []validatePeriod{
+				{StartAt: now, EndAt: now},
+				{StartAt: now.Add(-time.Hour), EndAt: now},
+			}},
+			wantPaths: []string{"periods[0].endAt", "periods[1]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vd := &validator{structs: options.structValidators}
+			err := validateWith(WithExecuteTime(context.Background(), now), tt.v, nil, vd)
+
+			var paths []string
+			var verr *ValidationError
+			if errors.As(err, &verr) {
+				for _, v := range verr.Violations {
+					if v.Constraint != ConstraintStruct {
+						t.Errorf("Constraint = %v, want %v", v.Constraint, ConstraintStruct)
+					}
+					paths = append(paths, v.Path)
+				}
+			} else if err != nil {
+				t.Fatalf("validateWith() error = %v", err)
+			}
+			if fmt.Sprintf("%q", paths) != fmt.Sprintf("%q", tt.wantPaths) {
+				t.Errorf("paths = %q, want %q", paths, tt.wantPaths)
+			}
+		})
+	}
+}